@@ -1,18 +1,56 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/pgabrielgmdeveloper/freightQuote/configs"
 	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
 	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra"
 	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/cache"
 	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/database"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/health"
 	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/http"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/logging"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/telemetry"
+	"io"
+	"log/slog"
+	nethttp "net/http"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
 
-	cfg, err := configs.LoadConfig()
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "arquivo de configuração YAML ou TOML; variáveis de ambiente têm prioridade")
+	printConfig := flag.Bool("print-config", false, "mostra a configuração efetiva, com os segredos mascarados, e sai")
+	flag.Parse()
+
+	if *printConfig {
+		os.Exit(printEffectiveConfig(*configFile))
+	}
+	cfg, err := configs.LoadConfig(*configFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	// só os tokens são mascarados por conteúdo: uma senha curta do banco
+	// apareceria mascarada dentro de mensagens legítimas
+	logging.RegisterSecret(cfg.TokenAPI, cfg.AdminToken)
+	logger, err := logging.New(os.Stdout, cfg.LogFormat, cfg.LogLevel)
+	if err != nil {
+		panic(err)
+	}
+	slog.SetDefault(logger)
+	shutdownTracing, err := telemetry.SetupTracing(context.Background(), cfg.TracingExporter)
+	if err != nil {
+		panic(err)
+	}
+
 	db, err := database.NewDbInstance(
 		cfg.DBDriver,
 		cfg.DBHost,
@@ -28,17 +66,172 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
-	redis := cache.NewRedisInstance(cfg.RedisHost, cfg.RedisPort)
-	redisCache := cache.NewRedisCache(redis)
-	repo := database.NewQuoteRepository(db)
+	quoteCache := newQuoteCache(cfg.CacheBackend, cfg.RedisHost, cfg.RedisPort)
+	repo := database.NewQuoteRepository(db, logger)
 	adapterMetrics := infra.NewMetricsAdapter(repo)
-	adapterSimulateQuote := infra.NewFreteRapidoAdapter(repo)
-	quoteService := quote.NewQuoteService(adapterSimulateQuote, adapterMetrics)
-	handlerQuoteServices := http.NewQuoteAdapterHandler(quoteService, quoteService, redisCache)
+	adapterFreteRapido := infra.NewFreteRapidoAdapter(infra.FreteRapidoConfig{
+		Timeout: cfg.FreteRapidoTimeout,
+		Retry: infra.RetryPolicy{
+			MaxRetries: cfg.FreteRapidoMaxRetries,
+			BaseDelay:  cfg.FreteRapidoRetryBaseDelay,
+			MaxDelay:   cfg.FreteRapidoRetryMaxDelay,
+		},
+		BreakerThreshold: cfg.FreteRapidoBreakerThreshold,
+		BreakerTimeout:   cfg.FreteRapidoBreakerTimeout,
+		Logger:           logger,
+	})
+	adapterSimulateQuote := infra.NewCachingQuoteAdapter(
+		infra.NewMultiCarrierAdapter(logger,
+			infra.CarrierProvider{Name: "frete_rapido", Provider: adapterFreteRapido},
+		),
+		quoteCache,
+		infra.CachingConfig{
+			TTL:                  cfg.CacheTTL,
+			StaleWhileRevalidate: cfg.CacheStaleWhileRevalidate,
+			MaxStaleness:         cfg.CacheMaxStaleness,
+			PartialTTL:           cfg.CachePartialTTL,
+			Logger:               logger,
+		},
+	)
+	quoteService := quote.NewQuoteService(adapterSimulateQuote, adapterMetrics, infra.NewQuoteHistoryAdapter(repo), logger)
+	warehouseService := quote.NewWarehouseService(newWarehousePort(cfg.WarehouseSource, cfg.Warehouses, cfg.RegisteredNumber, db))
+	handlerQuoteServices := http.NewQuoteAdapterHandler(quoteService, quoteService, warehouseService, quoteService, cfg.Shipper(), logger)
+	handlerCacheAdmin := http.NewCacheAdminHandler(adapterSimulateQuote, logger)
+	handlerHealth := http.NewHealthHandler(health.NewChecker(health.DefaultCheckTimeout,
+		health.Check{Name: "postgres", Critical: true, Probe: db.PingContext},
+		// sem o cache a simulação continua, indo sempre ao provedor
+		health.Check{Name: "cache", Probe: quoteCache.Ping},
+		health.Check{Name: "frete_rapido", Probe: adapterFreteRapido.HealthCheck},
+	))
+
+	r := gin.New()
+	r.Use(gin.Recovery())
+	// registradas antes dos demais middlewares para que as sondas do
+	// orquestrador não encham os logs, os traces e as métricas
+	r.GET("/healthz", handlerHealth.Liveness)
+	r.GET("/readyz", handlerHealth.Readiness)
 
-	r := gin.Default()
+	r.Use(http.RequestLogMiddleware(logger), http.TracingMiddleware(), http.TelemetryMiddleware())
 	r.POST("/simulate", handlerQuoteServices.SimulateQuote)
 	r.GET("/metrics", handlerQuoteServices.GetMetrics)
-	r.Run(":8000")
+	r.GET("/quotes", handlerQuoteServices.ListQuotes)
+	r.GET("/quotes/:id", handlerQuoteServices.GetQuote)
+
+	adminAuth := http.AdminAuthMiddleware(cfg.AdminToken)
+	if cfg.AdminToken == "" {
+		logger.Warn("ADMIN_TOKEN não configurado, /admin/cache e /internal/metrics ficam fechadas")
+	}
+	r.GET("/internal/metrics", adminAuth, http.InternalMetrics())
+
+	admin := r.Group("/admin/cache", adminAuth)
+	admin.GET("/stats", handlerCacheAdmin.GetStats)
+	admin.GET("/entries/*key", handlerCacheAdmin.GetEntry)
+	admin.DELETE("/zipcodes/:zipcode", handlerCacheAdmin.PurgeZipcode)
+	admin.DELETE("/skus/:sku", handlerCacheAdmin.PurgeSKU)
+	admin.DELETE("", handlerCacheAdmin.Flush)
 
+	server := http.NewServer(r, http.ServerConfig{
+		Port:           cfg.HTTPPort,
+		ReadTimeout:    cfg.HTTPReadTimeout,
+		WriteTimeout:   cfg.HTTPWriteTimeout,
+		IdleTimeout:    cfg.HTTPIdleTimeout,
+		MaxHeaderBytes: cfg.HTTPMaxHeaderBytes,
+	})
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("servidor HTTP iniciado", slog.String("addr", server.Addr))
+		serverErr <- server.ListenAndServe()
+	}()
+
+	// se o servidor não subir (porta ocupada, por exemplo) o processo ainda
+	// fecha cache e banco, mas sai com 1 para o orquestrador ver a falha
+	exitCode := 0
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, nethttp.ErrServerClosed) {
+			logger.Error("servidor HTTP parou", slog.String("error", err.Error()))
+			exitCode = 1
+		}
+	case <-ctx.Done():
+		logger.Info("sinal recebido, encerrando o servidor", slog.Duration("deadline", cfg.HTTPShutdownTimeout))
+	}
+	stop()
+
+	// a ordem importa: primeiro param de chegar requisições e as em andamento
+	// terminam, depois as atualizações de cache em segundo plano, e só então
+	// o cache e o banco são fechados
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTPShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("requisições em andamento não terminaram a tempo", slog.String("error", err.Error()))
+	}
+	if err := adapterSimulateQuote.Wait(shutdownCtx); err != nil {
+		logger.Error("atualizações de cache não terminaram a tempo", slog.String("error", err.Error()))
+	}
+	if closer, ok := quoteCache.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			logger.Error("erro ao fechar o cache", slog.String("error", err.Error()))
+		}
+	}
+	if err := db.Close(); err != nil {
+		logger.Error("erro ao fechar o banco", slog.String("error", err.Error()))
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error("não foi possível enviar os últimos spans", slog.String("error", err.Error()))
+	}
+	logger.Info("servidor encerrado", slog.Int("exit_code", exitCode))
+	if exitCode != 0 {
+		cancel()
+		os.Exit(exitCode)
+	}
+}
+
+// printEffectiveConfig mostra a configuração mesmo quando ela é inválida, para
+// facilitar o diagnóstico, e lista os problemas em seguida.
+func printEffectiveConfig(configFile string) int {
+	cfg, err := configs.Load(configFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := cfg.PrintRedacted(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// newQuoteCache usa Redis por padrão; CACHE_BACKEND=memory serve para
+// instalações de um único nó.
+func newQuoteCache(backend, redisHost, redisPort string) cache.IRedisCache {
+	if backend == "memory" {
+		return cache.NewMemoryCache()
+	}
+	return cache.NewRedisCache(cache.NewRedisInstance(redisHost, redisPort))
+}
+
+// newWarehousePort escolhe de onde vêm os centros de distribuição. Sem
+// WAREHOUSES configurado, mantém o centro padrão usado até então.
+func newWarehousePort(source, rawWarehouses, registeredNumber string, db *sql.DB) quote.WarehouseOutputPort {
+	if source == "database" {
+		return infra.NewWarehouseAdapter(database.NewWarehouseRepository(db))
+	}
+	if rawWarehouses == "" {
+		return infra.NewStaticWarehouseAdapter([]quote.Warehouse{{
+			ID:               "default",
+			Name:             "Centro de distribuição padrão",
+			RegisteredNumber: registeredNumber,
+			Zipcode:          1311000,
+			Active:           true,
+		}})
+	}
+	// WAREHOUSES já passou por configs.Validate na subida
+	warehouses, _ := infra.ParseWarehouses(rawWarehouses)
+	return infra.NewStaticWarehouseAdapter(warehouses)
 }
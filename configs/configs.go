@@ -1,38 +1,163 @@
 package configs
 
-import "github.com/spf13/viper"
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"strings"
+	"time"
+)
 
 type conf struct {
 	DBDriver         string `mapstructure:"DB_DRIVER"`
 	DBHost           string `mapstructure:"DB_HOST"`
 	DBPort           string `mapstructure:"DB_PORT"`
 	DBUser           string `mapstructure:"DB_USER"`
-	DBPassword       string `mapstructure:"DB_PASSWORD"`
+	DBPassword       string `mapstructure:"DB_PASSWORD" secret:"true"`
 	DBName           string `mapstructure:"DB_NAME"`
-	TokenAPI         string `mapstructure:"TOKEN_API"`
+	TokenAPI         string `mapstructure:"TOKEN_API" secret:"true"`
 	PlatformCode     string `mapstructure:"PLATFORM_CODE"`
 	RegisteredNumber string `mapstructure:"REGISTERED_NUMBER"`
 	RedisHost        string `mapstructure:"REDIS_HOST"`
 	RedisPort        string `mapstructure:"REDIS_PORT"`
+
+	FreteRapidoTimeout          time.Duration `mapstructure:"FRETE_RAPIDO_TIMEOUT"`
+	FreteRapidoMaxRetries       int           `mapstructure:"FRETE_RAPIDO_MAX_RETRIES"`
+	FreteRapidoRetryBaseDelay   time.Duration `mapstructure:"FRETE_RAPIDO_RETRY_BASE_DELAY"`
+	FreteRapidoRetryMaxDelay    time.Duration `mapstructure:"FRETE_RAPIDO_RETRY_MAX_DELAY"`
+	FreteRapidoBreakerThreshold int           `mapstructure:"FRETE_RAPIDO_BREAKER_THRESHOLD"`
+	FreteRapidoBreakerTimeout   time.Duration `mapstructure:"FRETE_RAPIDO_BREAKER_TIMEOUT"`
+
+	CacheBackend string        `mapstructure:"CACHE_BACKEND"`
+	CacheTTL     time.Duration `mapstructure:"CACHE_TTL"`
+
+	CacheStaleWhileRevalidate bool          `mapstructure:"CACHE_STALE_WHILE_REVALIDATE"`
+	CacheMaxStaleness         time.Duration `mapstructure:"CACHE_MAX_STALENESS"`
+	CachePartialTTL           time.Duration `mapstructure:"CACHE_PARTIAL_TTL"`
+
+	WarehouseSource string `mapstructure:"WAREHOUSE_SOURCE"`
+	Warehouses      string `mapstructure:"WAREHOUSES"`
+
+	HTTPPort            string        `mapstructure:"HTTP_PORT"`
+	HTTPReadTimeout     time.Duration `mapstructure:"HTTP_READ_TIMEOUT"`
+	HTTPWriteTimeout    time.Duration `mapstructure:"HTTP_WRITE_TIMEOUT"`
+	HTTPIdleTimeout     time.Duration `mapstructure:"HTTP_IDLE_TIMEOUT"`
+	HTTPMaxHeaderBytes  int           `mapstructure:"HTTP_MAX_HEADER_BYTES"`
+	HTTPShutdownTimeout time.Duration `mapstructure:"HTTP_SHUTDOWN_TIMEOUT"`
+
+	LogFormat string `mapstructure:"LOG_FORMAT"`
+	LogLevel  string `mapstructure:"LOG_LEVEL"`
+
+	// os demais OTEL_* (endpoint, cabeçalhos, protocolo, amostragem) são lidos
+	// direto do ambiente pelo SDK do OpenTelemetry
+	TracingExporter string `mapstructure:"OTEL_TRACES_EXPORTER"`
+
+	// protege /admin/cache e /internal/metrics; sem ele essas rotas ficam
+	// fechadas
+	AdminToken string `mapstructure:"ADMIN_TOKEN" secret:"true"`
 }
 
-func LoadConfig() (*conf, error) {
-	var cfg *conf
-	viper.AutomaticEnv()
-	viper.BindEnv("DB_DRIVER")
-	viper.BindEnv("DB_HOST")
-	viper.BindEnv("DB_PORT")
-	viper.BindEnv("DB_USER")
-	viper.BindEnv("DB_PASSWORD")
-	viper.BindEnv("DB_NAME")
-	viper.BindEnv("TOKEN_API")
-	viper.BindEnv("PLATFORM_CODE")
-	viper.BindEnv("REGISTERED_NUMBER")
-	viper.BindEnv("REDIS_HOST")
-	viper.BindEnv("REDIS_PORT")
-	err := viper.Unmarshal(&cfg)
+// LoadConfig lê e valida a configuração uma única vez, na subida do serviço.
+// Devolve todos os problemas encontrados de uma vez.
+func LoadConfig(configFile string) (*conf, error) {
+	cfg, err := Load(configFile)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Load lê a configuração sem validá-la. As variáveis de ambiente têm
+// prioridade sobre o arquivo em configFile (YAML ou TOML, com as mesmas chaves
+// das variáveis), que por sua vez tem prioridade sobre os valores padrão.
+// Qualquer chave pode vir de um arquivo indicado em <CHAVE>_FILE, como os
+// secrets do Docker e do Kubernetes montados em /run/secrets.
+func Load(configFile string) (*conf, error) {
+	v := viper.New()
+	if configFile != "" {
+		v.SetConfigFile(configFile)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("não foi possível ler o arquivo de configuração %s: %w", configFile, err)
+		}
+	}
+	v.AutomaticEnv()
+	v.BindEnv("DB_DRIVER")
+	v.BindEnv("DB_HOST")
+	v.BindEnv("DB_PORT")
+	v.BindEnv("DB_USER")
+	v.BindEnv("DB_PASSWORD")
+	v.BindEnv("DB_NAME")
+	v.BindEnv("TOKEN_API")
+	v.BindEnv("PLATFORM_CODE")
+	v.BindEnv("REGISTERED_NUMBER")
+	v.BindEnv("REDIS_HOST")
+	v.BindEnv("REDIS_PORT")
+	v.BindEnv("FRETE_RAPIDO_TIMEOUT")
+	v.SetDefault("FRETE_RAPIDO_MAX_RETRIES", 2)
+	v.BindEnv("FRETE_RAPIDO_MAX_RETRIES")
+	v.BindEnv("FRETE_RAPIDO_RETRY_BASE_DELAY")
+	v.BindEnv("FRETE_RAPIDO_RETRY_MAX_DELAY")
+	v.BindEnv("FRETE_RAPIDO_BREAKER_THRESHOLD")
+	v.BindEnv("FRETE_RAPIDO_BREAKER_TIMEOUT")
+	v.SetDefault("CACHE_BACKEND", "redis")
+	v.BindEnv("CACHE_BACKEND")
+	v.SetDefault("CACHE_TTL", "30m")
+	v.BindEnv("CACHE_TTL")
+	v.BindEnv("CACHE_STALE_WHILE_REVALIDATE")
+	v.SetDefault("CACHE_MAX_STALENESS", "10m")
+	v.BindEnv("CACHE_MAX_STALENESS")
+	v.SetDefault("CACHE_PARTIAL_TTL", "1m")
+	v.BindEnv("CACHE_PARTIAL_TTL")
+	v.SetDefault("WAREHOUSE_SOURCE", "config")
+	v.BindEnv("WAREHOUSE_SOURCE")
+	v.BindEnv("WAREHOUSES")
+	v.SetDefault("HTTP_PORT", "8000")
+	v.BindEnv("HTTP_PORT")
+	v.BindEnv("HTTP_READ_TIMEOUT")
+	v.BindEnv("HTTP_WRITE_TIMEOUT")
+	v.BindEnv("HTTP_IDLE_TIMEOUT")
+	v.BindEnv("HTTP_MAX_HEADER_BYTES")
+	v.SetDefault("HTTP_SHUTDOWN_TIMEOUT", "30s")
+	v.BindEnv("HTTP_SHUTDOWN_TIMEOUT")
+	v.SetDefault("LOG_FORMAT", "text")
+	v.BindEnv("LOG_FORMAT")
+	v.SetDefault("LOG_LEVEL", "info")
+	v.BindEnv("LOG_LEVEL")
+	v.SetDefault("OTEL_TRACES_EXPORTER", "none")
+	v.BindEnv("OTEL_TRACES_EXPORTER")
+	v.BindEnv("ADMIN_TOKEN")
+	if err := readSecretFiles(v); err != nil {
+		return nil, err
+	}
+	var cfg conf
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("configuração inválida: %w", err)
+	}
+	return &cfg, nil
+}
+
+// readSecretFiles aplica as variáveis <CHAVE>_FILE. Definir a chave e o
+// arquivo ao mesmo tempo é erro, para não haver dúvida sobre qual vale.
+func readSecretFiles(v *viper.Viper) error {
+	var errs ConfigError
+	for _, field := range fields() {
+		path := os.Getenv(field.key + "_FILE")
+		if path == "" {
+			continue
+		}
+		if os.Getenv(field.key) != "" {
+			errs.add(field.key+"_FILE", fmt.Sprintf("não use %s e %s_FILE ao mesmo tempo", field.key, field.key))
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			errs.add(field.key+"_FILE", fmt.Sprintf("não foi possível ler %s: %s", path, err.Error()))
+			continue
+		}
+		v.Set(field.key, strings.TrimRight(string(content), "\r\n"))
 	}
-	return cfg, err
+	return errs.errOrNil()
 }
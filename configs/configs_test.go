@@ -0,0 +1,190 @@
+package configs
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func setValidEnv(t *testing.T) {
+	for key, value := range map[string]string{
+		"DB_DRIVER":         "postgres",
+		"DB_HOST":           "localhost",
+		"DB_PORT":           "5432",
+		"DB_USER":           "frete",
+		"DB_PASSWORD":       "frete",
+		"DB_NAME":           "frete",
+		"TOKEN_API":         "token-de-teste-com-32-caracteres",
+		"PLATFORM_CODE":     "5AKVkHqCn",
+		"REGISTERED_NUMBER": "25438296000158",
+		"REDIS_HOST":        "localhost",
+		"REDIS_PORT":        "6379",
+	} {
+		t.Setenv(key, value)
+	}
+}
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadConfigFromEnv(t *testing.T) {
+	setValidEnv(t)
+	t.Setenv("CACHE_TTL", "5m")
+
+	cfg, err := LoadConfig("")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "localhost", cfg.DBHost)
+	assert.Equal(t, 5*time.Minute, cfg.CacheTTL)
+	assert.Equal(t, "8000", cfg.HTTPPort)
+	assert.Equal(t, "25438296000158", cfg.Shipper().RegisteredNumber)
+}
+
+func TestLoadConfigAggregatesProblems(t *testing.T) {
+	setValidEnv(t)
+	t.Setenv("TOKEN_API", "curto")
+	t.Setenv("REGISTERED_NUMBER", "11111111111111")
+	t.Setenv("DB_HOST", "")
+	t.Setenv("REDIS_PORT", "redis")
+	t.Setenv("LOG_FORMAT", "xml")
+	t.Setenv("ADMIN_TOKEN", "curto")
+
+	_, err := LoadConfig("")
+
+	var configErr *ConfigError
+	assert.True(t, errors.As(err, &configErr))
+	var keys []string
+	for _, p := range configErr.Problems {
+		keys = append(keys, p.Key)
+	}
+	assert.ElementsMatch(t, []string{"REGISTERED_NUMBER", "TOKEN_API", "DB_HOST", "REDIS_PORT", "LOG_FORMAT", "ADMIN_TOKEN"}, keys)
+	assert.Contains(t, err.Error(), "TOKEN_API: token deve ter 32 caracteres")
+}
+
+func TestLoadConfigReportsInvalidWarehouses(t *testing.T) {
+	setValidEnv(t)
+	t.Setenv("TOKEN_API", "curto")
+	t.Setenv("WAREHOUSES", `[{"id": "sp-01", "registered_number": "11111111111111", "zipcode": 1311000}]`)
+
+	_, err := LoadConfig("")
+
+	var configErr *ConfigError
+	assert.True(t, errors.As(err, &configErr))
+	assert.Len(t, configErr.Problems, 2)
+	assert.Contains(t, err.Error(), "WAREHOUSES: [0].registered_number: CNPJ do centro de distribuição inválido")
+
+	t.Setenv("TOKEN_API", "token-de-teste-com-32-caracteres")
+	t.Setenv("WAREHOUSES", "{não é json")
+	_, err = LoadConfig("")
+	assert.ErrorContains(t, err, "WAREHOUSES: JSON inválido")
+}
+
+func TestLoadConfigRedisSettingsOnlyRequiredForRedisBackend(t *testing.T) {
+	setValidEnv(t)
+	t.Setenv("REDIS_HOST", "")
+	t.Setenv("CACHE_BACKEND", "memory")
+
+	_, err := LoadConfig("")
+
+	assert.Nil(t, err)
+}
+
+func TestLoadConfigFromYAMLFileWithEnvOverride(t *testing.T) {
+	setValidEnv(t)
+	os.Unsetenv("DB_HOST")
+	t.Setenv("HTTP_PORT", "9090")
+	path := writeConfigFile(t, "config.yaml", `
+db_host: banco.interno
+http_port: "8080"
+cache_ttl: 10m
+cache_stale_while_revalidate: true
+`)
+
+	cfg, err := LoadConfig(path)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "banco.interno", cfg.DBHost)
+	assert.Equal(t, "9090", cfg.HTTPPort)
+	assert.Equal(t, 10*time.Minute, cfg.CacheTTL)
+	assert.True(t, cfg.CacheStaleWhileRevalidate)
+}
+
+func TestLoadConfigFromTOMLFile(t *testing.T) {
+	setValidEnv(t)
+	path := writeConfigFile(t, "config.toml", `
+warehouse_source = "database"
+frete_rapido_max_retries = 4
+`)
+
+	cfg, err := LoadConfig(path)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "database", cfg.WarehouseSource)
+	assert.Equal(t, 4, cfg.FreteRapidoMaxRetries)
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	setValidEnv(t)
+
+	_, err := LoadConfig(filepath.Join(t.TempDir(), "nao-existe.yaml"))
+
+	assert.ErrorContains(t, err, "nao-existe.yaml")
+}
+
+func TestLoadConfigReadsSecretFiles(t *testing.T) {
+	setValidEnv(t)
+	os.Unsetenv("TOKEN_API")
+	os.Unsetenv("DB_PASSWORD")
+	t.Setenv("TOKEN_API_FILE", writeConfigFile(t, "token_api", "token-de-teste-com-32-caracteres\n"))
+	t.Setenv("DB_PASSWORD_FILE", writeConfigFile(t, "db_password", "s3nh4"))
+
+	cfg, err := LoadConfig("")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "token-de-teste-com-32-caracteres", cfg.TokenAPI)
+	assert.Equal(t, "s3nh4", cfg.DBPassword)
+}
+
+func TestLoadConfigRejectsValueAndSecretFileTogether(t *testing.T) {
+	setValidEnv(t)
+	t.Setenv("TOKEN_API_FILE", writeConfigFile(t, "token_api", "token-de-teste-com-32-caracteres"))
+	t.Setenv("DB_PASSWORD_FILE", filepath.Join(t.TempDir(), "nao-existe"))
+	os.Unsetenv("DB_PASSWORD")
+
+	_, err := LoadConfig("")
+
+	var configErr *ConfigError
+	assert.True(t, errors.As(err, &configErr))
+	assert.Len(t, configErr.Problems, 2)
+	assert.Equal(t, "DB_PASSWORD_FILE", configErr.Problems[0].Key)
+	assert.Contains(t, configErr.Problems[0].Message, "nao-existe")
+	assert.Equal(t, "TOKEN_API_FILE", configErr.Problems[1].Key)
+	assert.Contains(t, configErr.Problems[1].Message, "ao mesmo tempo")
+}
+
+func TestPrintRedactedHidesSecrets(t *testing.T) {
+	setValidEnv(t)
+	t.Setenv("ADMIN_TOKEN", "token-administrativo")
+	cfg, err := LoadConfig("")
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	assert.Nil(t, cfg.PrintRedacted(&buf))
+
+	out := buf.String()
+	assert.NotContains(t, out, "token-de-teste-com-32-caracteres")
+	assert.Contains(t, out, "TOKEN_API=[REDACTED]\n")
+	assert.Contains(t, out, "DB_PASSWORD=[REDACTED]\n")
+	assert.Contains(t, out, "ADMIN_TOKEN=[REDACTED]\n")
+	assert.NotContains(t, out, "token-administrativo")
+	assert.Contains(t, out, "DB_HOST=localhost\n")
+	assert.Contains(t, out, "CACHE_TTL=30m0s\n")
+	assert.Contains(t, out, "CACHE_PARTIAL_TTL=1m0s\n")
+}
@@ -0,0 +1,47 @@
+package configs
+
+import (
+	"fmt"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/logging"
+	"io"
+	"reflect"
+)
+
+type field struct {
+	key    string
+	secret bool
+	index  int
+}
+
+// fields lista as chaves da configuração na ordem da struct, marcando as que
+// têm a tag secret.
+func fields() []field {
+	t := reflect.TypeOf(conf{})
+	result := make([]field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag
+		result = append(result, field{
+			key:    tag.Get("mapstructure"),
+			secret: tag.Get("secret") == "true",
+			index:  i,
+		})
+	}
+	return result
+}
+
+// PrintRedacted escreve a configuração efetiva, uma chave por linha no
+// formato CHAVE=valor, com os segredos trocados por logging.Redacted. Segredos
+// vazios continuam vazios, para mostrar que não foram definidos.
+func (c *conf) PrintRedacted(w io.Writer) error {
+	value := reflect.ValueOf(*c)
+	for _, f := range fields() {
+		printed := fmt.Sprint(value.Field(f.index).Interface())
+		if f.secret && printed != "" {
+			printed = logging.Redacted
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", f.key, printed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const minAdminTokenLength = 16
+
+// ConfigError reúne todos os problemas da configuração, para que sejam
+// corrigidos de uma vez em vez de um por subida.
+type ConfigError struct {
+	Problems []ConfigProblem
+}
+
+type ConfigProblem struct {
+	Key     string
+	Message string
+}
+
+func (e *ConfigError) Error() string {
+	var b strings.Builder
+	b.WriteString("configuração inválida:")
+	for _, p := range e.Problems {
+		fmt.Fprintf(&b, "\n  - %s: %s", p.Key, p.Message)
+	}
+	return b.String()
+}
+
+func (e *ConfigError) add(key, message string) {
+	e.Problems = append(e.Problems, ConfigProblem{Key: key, Message: message})
+}
+
+func (e *ConfigError) errOrNil() error {
+	if len(e.Problems) == 0 {
+		return nil
+	}
+	return e
+}
+
+// shipperKeys traduz os campos validados pelo domínio para as chaves de
+// configuração de onde vieram.
+var shipperKeys = map[string]string{
+	"registered_number": "REGISTERED_NUMBER",
+	"token":             "TOKEN_API",
+	"platform_code":     "PLATFORM_CODE",
+}
+
+// Validate confere a configuração na subida. O remetente passa pelas mesmas
+// regras do domínio aplicadas a cada cotação (CNPJ e token de 32 caracteres).
+func (c *conf) Validate() error {
+	var errs ConfigError
+
+	shipper := c.Shipper()
+	var shipperErrs quote.ValidationErrors
+	if errors.As(shipper.Validate(), &shipperErrs) {
+		for _, v := range shipperErrs {
+			errs.add(shipperKeys[v.Field], v.Message)
+		}
+	}
+
+	required := func(key, value string) {
+		if strings.TrimSpace(value) == "" {
+			errs.add(key, "obrigatório")
+		}
+	}
+	port := func(key, value string) {
+		if value == "" {
+			return
+		}
+		if n, err := strconv.Atoi(value); err != nil || n < 1 || n > 65535 {
+			errs.add(key, fmt.Sprintf("porta inválida %q", value))
+		}
+	}
+	oneOf := func(key, value string, allowed ...string) {
+		for _, a := range allowed {
+			if value == a {
+				return
+			}
+		}
+		errs.add(key, fmt.Sprintf("valor %q inválido, use %s", value, strings.Join(allowed, ", ")))
+	}
+	notNegative := func(key string, value time.Duration) {
+		if value < 0 {
+			errs.add(key, "não pode ser negativo")
+		}
+	}
+
+	required("DB_DRIVER", c.DBDriver)
+	required("DB_HOST", c.DBHost)
+	required("DB_PORT", c.DBPort)
+	port("DB_PORT", c.DBPort)
+	required("DB_USER", c.DBUser)
+	required("DB_NAME", c.DBName)
+
+	if c.AdminToken != "" && len(c.AdminToken) < minAdminTokenLength {
+		errs.add("ADMIN_TOKEN", fmt.Sprintf("deve ter ao menos %d caracteres", minAdminTokenLength))
+	}
+
+	oneOf("CACHE_BACKEND", c.CacheBackend, "redis", "memory")
+	if c.CacheBackend == "redis" {
+		required("REDIS_HOST", c.RedisHost)
+		required("REDIS_PORT", c.RedisPort)
+		port("REDIS_PORT", c.RedisPort)
+	}
+	notNegative("CACHE_TTL", c.CacheTTL)
+	notNegative("CACHE_MAX_STALENESS", c.CacheMaxStaleness)
+	notNegative("CACHE_PARTIAL_TTL", c.CachePartialTTL)
+
+	notNegative("FRETE_RAPIDO_TIMEOUT", c.FreteRapidoTimeout)
+	if c.FreteRapidoMaxRetries < 0 {
+		errs.add("FRETE_RAPIDO_MAX_RETRIES", "não pode ser negativo")
+	}
+	notNegative("FRETE_RAPIDO_RETRY_BASE_DELAY", c.FreteRapidoRetryBaseDelay)
+	notNegative("FRETE_RAPIDO_RETRY_MAX_DELAY", c.FreteRapidoRetryMaxDelay)
+	notNegative("FRETE_RAPIDO_BREAKER_TIMEOUT", c.FreteRapidoBreakerTimeout)
+
+	oneOf("WAREHOUSE_SOURCE", c.WarehouseSource, "config", "database")
+	if c.WarehouseSource == "config" && c.Warehouses != "" {
+		if _, err := infra.ParseWarehouses(c.Warehouses); err != nil {
+			var violations quote.ValidationErrors
+			if errors.As(err, &violations) {
+				for _, v := range violations {
+					errs.add("WAREHOUSES", v.Field+": "+v.Message)
+				}
+			} else {
+				errs.add("WAREHOUSES", err.Error())
+			}
+		}
+	}
+
+	port("HTTP_PORT", c.HTTPPort)
+	notNegative("HTTP_READ_TIMEOUT", c.HTTPReadTimeout)
+	notNegative("HTTP_WRITE_TIMEOUT", c.HTTPWriteTimeout)
+	notNegative("HTTP_IDLE_TIMEOUT", c.HTTPIdleTimeout)
+	if c.HTTPShutdownTimeout <= 0 {
+		errs.add("HTTP_SHUTDOWN_TIMEOUT", "deve ser maior que zero")
+	}
+
+	oneOf("LOG_FORMAT", strings.ToLower(c.LogFormat), "text", "json")
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		errs.add("LOG_LEVEL", fmt.Sprintf("valor %q inválido, use debug, info, warn, error", c.LogLevel))
+	}
+
+	oneOf("OTEL_TRACES_EXPORTER", c.TracingExporter, "none", "otlp", "console")
+
+	return errs.errOrNil()
+}
+
+// Shipper é o remetente enviado à Frete Rápido em todas as cotações.
+func (c *conf) Shipper() quote.Shipper {
+	return quote.Shipper{
+		RegisteredNumber: c.RegisteredNumber,
+		Token:            c.TokenAPI,
+		PlatformCode:     c.PlatformCode,
+	}
+}
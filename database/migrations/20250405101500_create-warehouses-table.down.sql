@@ -0,0 +1 @@
+DROP TABLE warehouses;
\ No newline at end of file
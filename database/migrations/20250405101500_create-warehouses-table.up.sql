@@ -0,0 +1,8 @@
+CREATE TABLE warehouses (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    registered_number VARCHAR(14) NOT NULL,
+    zipcode INTEGER NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
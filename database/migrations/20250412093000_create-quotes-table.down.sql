@@ -0,0 +1,6 @@
+ALTER TABLE offers
+    DROP COLUMN quote_id,
+    DROP COLUMN source,
+    DROP COLUMN warehouse_id;
+
+DROP TABLE quotes;
@@ -0,0 +1,28 @@
+CREATE TABLE quotes (
+    id VARCHAR(32) PRIMARY KEY,
+    -- X-Request-ID da requisição que gerou a cotação, quando houver
+    request_id VARCHAR(128),
+    recipient_type INTEGER NOT NULL,
+    recipient_zipcode INTEGER NOT NULL,
+    recipient_registered_number VARCHAR(14),
+    origin JSONB NOT NULL,
+    volumes JSONB NOT NULL,
+    total_weight DECIMAL NOT NULL,
+    declared_value DECIMAL NOT NULL,
+    upstream_latency_ms INTEGER NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    -- servida do cache: as ofertas repetem as de uma cotação anterior e ficam
+    -- fora das métricas
+    cached BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_quotes_created_at ON quotes (created_at);
+CREATE INDEX idx_quotes_recipient_zipcode ON quotes (recipient_zipcode);
+
+ALTER TABLE offers
+    ADD COLUMN quote_id VARCHAR(32) REFERENCES quotes (id) ON DELETE CASCADE,
+    ADD COLUMN source VARCHAR(255),
+    ADD COLUMN warehouse_id VARCHAR(64);
+
+CREATE INDEX idx_offers_quote_id ON offers (quote_id);
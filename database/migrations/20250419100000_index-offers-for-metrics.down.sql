@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_offers_carrier_service;
+DROP INDEX IF EXISTS idx_offers_created_at;
@@ -0,0 +1,2 @@
+CREATE INDEX idx_offers_created_at ON offers (created_at);
+CREATE INDEX idx_offers_carrier_service ON offers (lower(carrier), lower(service));
@@ -0,0 +1,12 @@
+ALTER TABLE offers ADD COLUMN weight DECIMAL;
+
+-- peso transportado por cada oferta já gravada: a soma dos volumes do mesmo
+-- centro de distribuição da oferta
+UPDATE offers o
+SET weight = (
+    SELECT sum((v ->> 'amount')::numeric * (v ->> 'unitary_weight')::numeric)
+    FROM quotes q, jsonb_array_elements(q.volumes) v
+    WHERE q.id = o.quote_id
+      AND coalesce(v ->> 'warehouse_id', '') = coalesce(o.warehouse_id, '')
+)
+WHERE o.quote_id IS NOT NULL;
@@ -0,0 +1 @@
+DROP TABLE cep_ranges;
@@ -0,0 +1,43 @@
+CREATE TABLE cep_ranges (
+    uf CHAR(2) NOT NULL,
+    region VARCHAR(32) NOT NULL,
+    start_cep INTEGER NOT NULL,
+    end_cep INTEGER NOT NULL,
+    CHECK (start_cep <= end_cep)
+);
+
+CREATE INDEX idx_cep_ranges_start_end ON cep_ranges (start_cep, end_cep);
+
+-- faixas de CEP dos Correios por UF; os CEPs são gravados como inteiro, sem
+-- os zeros à esquerda
+INSERT INTO cep_ranges (uf, region, start_cep, end_cep) VALUES
+    ('SP', 'Sudeste', 1000000, 19999999),
+    ('RJ', 'Sudeste', 20000000, 28999999),
+    ('ES', 'Sudeste', 29000000, 29999999),
+    ('MG', 'Sudeste', 30000000, 39999999),
+    ('BA', 'Nordeste', 40000000, 48999999),
+    ('SE', 'Nordeste', 49000000, 49999999),
+    ('PE', 'Nordeste', 50000000, 56999999),
+    ('AL', 'Nordeste', 57000000, 57999999),
+    ('PB', 'Nordeste', 58000000, 58999999),
+    ('RN', 'Nordeste', 59000000, 59999999),
+    ('CE', 'Nordeste', 60000000, 63999999),
+    ('PI', 'Nordeste', 64000000, 64999999),
+    ('MA', 'Nordeste', 65000000, 65999999),
+    ('PA', 'Norte', 66000000, 68899999),
+    ('AP', 'Norte', 68900000, 68999999),
+    ('AM', 'Norte', 69000000, 69299999),
+    ('RR', 'Norte', 69300000, 69399999),
+    ('AM', 'Norte', 69400000, 69899999),
+    ('AC', 'Norte', 69900000, 69999999),
+    ('DF', 'Centro-Oeste', 70000000, 72799999),
+    ('GO', 'Centro-Oeste', 72800000, 72999999),
+    ('DF', 'Centro-Oeste', 73000000, 73699999),
+    ('GO', 'Centro-Oeste', 73700000, 76799999),
+    ('RO', 'Norte', 76800000, 76999999),
+    ('TO', 'Norte', 77000000, 77999999),
+    ('MT', 'Centro-Oeste', 78000000, 78899999),
+    ('MS', 'Centro-Oeste', 79000000, 79999999),
+    ('PR', 'Sul', 80000000, 87999999),
+    ('SC', 'Sul', 88000000, 89999999),
+    ('RS', 'Sul', 90000000, 99999999);
@@ -0,0 +1,20 @@
+#!/bin/sh
+# Roda as migrações e sobe a aplicação. A senha do banco vem do secret em
+# DB_PASSWORD_FILE (ou de DB_PASSWORD) e chega ao migrate por PGPASSWORD, fora
+# da URL de conexão e da linha de comando.
+set -eu
+
+if [ -n "${DB_PASSWORD_FILE:-}" ]; then
+	PGPASSWORD="$(cat "$DB_PASSWORD_FILE")"
+else
+	PGPASSWORD="${DB_PASSWORD:-}"
+fi
+export PGPASSWORD
+
+migrate -path /app/database/migrations \
+	-database "postgres://${DB_USER}@${DB_HOST}:${DB_PORT:-5432}/${DB_NAME}?sslmode=disable" up
+unset PGPASSWORD
+
+# o exec faz o SIGTERM do docker chegar à aplicação, que então encerra as
+# requisições em andamento
+exec ./main
@@ -1,6 +1,7 @@
 package quote
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -14,7 +15,7 @@ func TestShipper_Validate(t *testing.T) {
 		{
 			name: "Shipper válido",
 			shipper: Shipper{
-				RegisteredNumber: "12345678901234", // CNPJ válido
+				RegisteredNumber: "11222333000181", // CNPJ válido
 				Token:            "abcdefghijklmnopqrstuvwxyz123456",
 				PlatformCode:     "PLAT123",
 			},
@@ -33,7 +34,7 @@ func TestShipper_Validate(t *testing.T) {
 		{
 			name: "Token inválido",
 			shipper: Shipper{
-				RegisteredNumber: "12345678901234",
+				RegisteredNumber: "11222333000181",
 				Token:            "curto", // Token inválido
 				PlatformCode:     "PLAT123",
 			},
@@ -43,7 +44,7 @@ func TestShipper_Validate(t *testing.T) {
 		{
 			name: "PlatformCode vazio",
 			shipper: Shipper{
-				RegisteredNumber: "12345678901234",
+				RegisteredNumber: "11222333000181",
 				Token:            "abcdefghijklmnopqrstuvwxyz123456",
 				PlatformCode:     "", // PlatformCode inválido
 			},
@@ -79,7 +80,7 @@ func TestRecipient_Validate(t *testing.T) {
 				Type:             1,
 				Country:          "BRA",
 				Zipcode:          12345678,
-				RegisteredNumber: "12345678901234", // CNPJ válido
+				RegisteredNumber: "11222333000181", // CNPJ válido
 			},
 			expectedError: false,
 		},
@@ -89,7 +90,7 @@ func TestRecipient_Validate(t *testing.T) {
 				Type:             0,
 				Country:          "BRA",
 				Zipcode:          12345678,
-				RegisteredNumber: "12345678901", // CPF válido
+				RegisteredNumber: "52998224725", // CPF válido
 			},
 			expectedError: false,
 		},
@@ -249,7 +250,7 @@ func TestVolume_Validate(t *testing.T) {
 				Length:        0,
 			},
 			expectedError: true,
-			errMsg:        "dimensões devem ser maiores que zero",
+			errMsg:        "altura deve ser maior que zero; largura deve ser maior que zero; comprimento deve ser maior que zero",
 		},
 	}
 
@@ -297,7 +298,7 @@ func TestDispatcher_Validate(t *testing.T) {
 		{
 			name: "Dispatcher válido",
 			dispatcher: Dispatcher{
-				RegisteredNumber: "12345678901234",
+				RegisteredNumber: "11222333000181",
 				Zipcode:          12345678,
 				Volumes:          []Volume{validVolume},
 			},
@@ -316,7 +317,7 @@ func TestDispatcher_Validate(t *testing.T) {
 		{
 			name: "CEP inválido",
 			dispatcher: Dispatcher{
-				RegisteredNumber: "12345678901234",
+				RegisteredNumber: "11222333000181",
 				Zipcode:          123,
 				Volumes:          []Volume{validVolume},
 			},
@@ -326,7 +327,7 @@ func TestDispatcher_Validate(t *testing.T) {
 		{
 			name: "Sem volumes",
 			dispatcher: Dispatcher{
-				RegisteredNumber: "12345678901234",
+				RegisteredNumber: "11222333000181",
 				Zipcode:          12345678,
 				Volumes:          []Volume{},
 			},
@@ -336,7 +337,7 @@ func TestDispatcher_Validate(t *testing.T) {
 		{
 			name: "Volume inválido",
 			dispatcher: Dispatcher{
-				RegisteredNumber: "12345678901234",
+				RegisteredNumber: "11222333000181",
 				Zipcode:          12345678,
 				Volumes:          []Volume{invalidVolume},
 			},
@@ -362,7 +363,7 @@ func TestDispatcher_Validate(t *testing.T) {
 func TestQuoteRequest_Validate(t *testing.T) {
 	validRequest := QuoteRequest{
 		Shipper: Shipper{
-			RegisteredNumber: "12345678901234",
+			RegisteredNumber: "11222333000181",
 			Token:            "abcdefghijklmnopqrstuvwxyz123456",
 			PlatformCode:     "PLAT123",
 		},
@@ -370,11 +371,11 @@ func TestQuoteRequest_Validate(t *testing.T) {
 			Type:             1,
 			Country:          "BRA",
 			Zipcode:          49160000,
-			RegisteredNumber: "12345678901234",
+			RegisteredNumber: "11222333000181",
 		},
 		Dispatchers: []Dispatcher{
 			{
-				RegisteredNumber: "12345678901234",
+				RegisteredNumber: "11222333000181",
 				Zipcode:          12345678,
 				Volumes: []Volume{
 					{
@@ -426,3 +427,222 @@ func TestQuoteRequest_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestQuoteRequest_ValidateFieldPath(t *testing.T) {
+	request := ValidRequest()
+	request.Dispatchers[0].Volumes[1].Amount = 0
+
+	err := request.Validate()
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("QuoteRequest.Validate() error = %v, expected *ValidationError", err)
+	}
+	if validationErr.Field != "dispatchers[0].volumes[1].amount" {
+		t.Errorf("QuoteRequest.Validate() field = %v, expected dispatchers[0].volumes[1].amount", validationErr.Field)
+	}
+}
+
+func TestQuoteRequest_ValidateCollectsAllViolations(t *testing.T) {
+	request := ValidRequest()
+	request.Recipient.Country = "USA"
+	request.Dispatchers[0].Volumes[0].Height = 0
+	request.Dispatchers[0].Volumes[1].Amount = 0
+	request.Dispatchers[0].Volumes[1].UnitaryWeight = -1
+
+	err := request.Validate()
+
+	var validationErrs ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		t.Fatalf("QuoteRequest.Validate() error = %v, expected ValidationErrors", err)
+	}
+	expectedFields := []string{
+		"recipient.country",
+		"dispatchers[0].volumes[0].height",
+		"dispatchers[0].volumes[1].amount",
+		"dispatchers[0].volumes[1].unitary_weight",
+	}
+	if len(validationErrs) != len(expectedFields) {
+		t.Fatalf("QuoteRequest.Validate() returned %d violations, expected %d: %v", len(validationErrs), len(expectedFields), err)
+	}
+	for i, field := range expectedFields {
+		if validationErrs[i].Field != field {
+			t.Errorf("violation[%d].Field = %v, expected %v", i, validationErrs[i].Field, field)
+		}
+	}
+}
+
+func TestIsValidCNPJ(t *testing.T) {
+	tests := []struct {
+		name     string
+		cnpj     string
+		expected bool
+	}{
+		{name: "CNPJ válido", cnpj: "25438296000158", expected: true},
+		{name: "CNPJ formatado", cnpj: "25.438.296/0001-58", expected: true},
+		{name: "CNPJ alfanumérico", cnpj: "12ABC34501DE35", expected: true},
+		{name: "CNPJ alfanumérico formatado e minúsculo", cnpj: "12.abc.345/01de-35", expected: true},
+		{name: "Dígito verificador errado", cnpj: "25438296000159", expected: false},
+		{name: "Dígito verificador alfanumérico errado", cnpj: "12ABC34501DE36", expected: false},
+		{name: "Sequência repetida", cnpj: "00000000000000", expected: false},
+		{name: "Sequência repetida formatada", cnpj: "11.111.111/1111-11", expected: false},
+		{name: "Letra no dígito verificador", cnpj: "12ABC34501DE3A", expected: false},
+		{name: "Tamanho inválido", cnpj: "2543829600015", expected: false},
+		{name: "Caractere inválido", cnpj: "25438296#00158", expected: false},
+		{name: "Vazio", cnpj: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isValidCNPJ(tt.cnpj); got != tt.expected {
+				t.Errorf("isValidCNPJ(%q) = %v, expected %v", tt.cnpj, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsValidCPF(t *testing.T) {
+	tests := []struct {
+		name     string
+		cpf      string
+		expected bool
+	}{
+		{name: "CPF válido", cpf: "52998224725", expected: true},
+		{name: "CPF formatado", cpf: "529.982.247-25", expected: true},
+		{name: "CPF com dígito zero", cpf: "12345678909", expected: true},
+		{name: "Dígito verificador errado", cpf: "52998224726", expected: false},
+		{name: "Sequência repetida", cpf: "00000000000", expected: false},
+		{name: "Sequência repetida formatada", cpf: "999.999.999-99", expected: false},
+		{name: "Letras", cpf: "5299822472A", expected: false},
+		{name: "Tamanho inválido", cpf: "5299822472", expected: false},
+		{name: "Vazio", cpf: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isValidCPF(tt.cpf); got != tt.expected {
+				t.Errorf("isValidCPF(%q) = %v, expected %v", tt.cpf, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestQuoteRequest_Normalize(t *testing.T) {
+	request := ValidRequest()
+	request.Shipper.RegisteredNumber = "25.438.296/0001-58"
+	request.Dispatchers[0].RegisteredNumber = "12.abc.345/01de-35"
+
+	request.Normalize()
+
+	if request.Shipper.RegisteredNumber != "25438296000158" {
+		t.Errorf("Shipper.RegisteredNumber = %v, expected 25438296000158", request.Shipper.RegisteredNumber)
+	}
+	if request.Dispatchers[0].RegisteredNumber != "12ABC34501DE35" {
+		t.Errorf("Dispatchers[0].RegisteredNumber = %v, expected 12ABC34501DE35", request.Dispatchers[0].RegisteredNumber)
+	}
+}
+
+func TestOfferCombinations(t *testing.T) {
+	dispatchers := []Dispatcher{{WarehouseID: "sp-01"}, {WarehouseID: "se-01"}}
+	offers := []Offer{
+		{WarehouseID: "sp-01", Carrier: "CORREIOS", FinalPrice: 30.10, DeliveryTime: 5},
+		{WarehouseID: "sp-01", Carrier: "JADLOG", FinalPrice: 45.50, DeliveryTime: 2},
+		{WarehouseID: "se-01", Carrier: "CORREIOS", FinalPrice: 20.20, DeliveryTime: 7},
+		{WarehouseID: "se-01", Carrier: "AZUL", FinalPrice: 60, DeliveryTime: 1},
+		{WarehouseID: "se-01", Carrier: "LATAM", FinalPrice: 55, DeliveryTime: 1},
+	}
+
+	groups := GroupOffersByWarehouse(dispatchers, offers)
+	if len(groups) != 2 || len(groups[0].Offers) != 2 || len(groups[1].Offers) != 3 {
+		t.Fatalf("GroupOffersByWarehouse() = %v", groups)
+	}
+
+	cheapest := CheapestCombination(groups)
+	if cheapest.TotalPrice != 50.30 || cheapest.DeliveryTime != 7 {
+		t.Errorf("CheapestCombination() = %v, expected price 50.30 and deadline 7", cheapest)
+	}
+
+	fastest := FastestCombination(groups)
+	if fastest.TotalPrice != 100.50 || fastest.DeliveryTime != 2 {
+		t.Errorf("FastestCombination() = %v, expected price 100.50 and deadline 2", fastest)
+	}
+	if fastest.Offers[1].Carrier != "LATAM" {
+		t.Errorf("FastestCombination() should break ties by price, got %v", fastest.Offers[1].Carrier)
+	}
+}
+
+func TestOfferCombinations_DispatcherWithoutOffers(t *testing.T) {
+	dispatchers := []Dispatcher{{WarehouseID: "sp-01"}, {WarehouseID: "se-01"}}
+	offers := []Offer{{WarehouseID: "sp-01", Carrier: "CORREIOS", FinalPrice: 30, DeliveryTime: 5}}
+
+	groups := GroupOffersByWarehouse(dispatchers, offers)
+
+	if CheapestCombination(groups) != nil || FastestCombination(groups) != nil {
+		t.Errorf("combination should be nil when a dispatcher has no offers")
+	}
+}
+
+func TestQuote_TotalWeightAndDeclaredValue(t *testing.T) {
+	q := Quote{Request: ValidRequest()}
+
+	if got := q.TotalWeight(); got != 13 {
+		t.Errorf("Quote.TotalWeight() = %v, expected 13", got)
+	}
+	if got := q.DeclaredValue(); got != 1461 {
+		t.Errorf("Quote.DeclaredValue() = %v, expected 1461", got)
+	}
+}
+
+func TestQuote_OfferWeight(t *testing.T) {
+	q := Quote{Request: QuoteRequest{Dispatchers: []Dispatcher{
+		{WarehouseID: "sp-01", Volumes: []Volume{{Amount: 2, UnitaryWeight: 4}}},
+		{WarehouseID: "se-01", Volumes: []Volume{{Amount: 1, UnitaryWeight: 5}}},
+	}}}
+
+	if got := q.OfferWeight(Offer{WarehouseID: "se-01"}); got != 5 {
+		t.Errorf("Quote.OfferWeight(se-01) = %v, expected 5", got)
+	}
+	if got := q.OfferWeight(Offer{}); got != 13 {
+		t.Errorf("Quote.OfferWeight(sem expedidor) = %v, expected 13", got)
+	}
+}
+
+func TestNewRegionMetrics(t *testing.T) {
+	region := NewRegionMetrics("Nordeste", []RegionCarrierMetrics{
+		{Name: "CORREIOS", TotalOffer: 4, AvgPrice: 40, AvgDeliveryTime: 3},
+		{Name: "JADLOG", TotalOffer: 2, AvgPrice: 32.5, AvgDeliveryTime: 6},
+		{Name: "AZUL CARGO", TotalOffer: 1, AvgPrice: 55, AvgDeliveryTime: 3},
+	})
+
+	if region.TotalOffer != 7 {
+		t.Errorf("TotalOffer = %d, expected 7", region.TotalOffer)
+	}
+	if region.Cheapest.Name != "JADLOG" {
+		t.Errorf("Cheapest = %s, expected JADLOG", region.Cheapest.Name)
+	}
+	// empate no prazo é decidido pelo preço
+	if region.Fastest.Name != "CORREIOS" {
+		t.Errorf("Fastest = %s, expected CORREIOS", region.Fastest.Name)
+	}
+}
+
+func TestMetricsFilter_ValidateRegion(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  MetricsFilter
+		wantErr bool
+	}{
+		{"Sem região", MetricsFilter{}, false},
+		{"Por UF", MetricsFilter{RegionBy: RegionByUF}, false},
+		{"Por prefixo de CEP", MetricsFilter{RegionBy: RegionByCEPPrefix, CEPPrefixLength: 3}, false},
+		{"Agrupamento desconhecido", MetricsFilter{RegionBy: "cidade"}, true},
+		{"Prefixo longo demais", MetricsFilter{RegionBy: RegionByCEPPrefix, CEPPrefixLength: 8}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("MetricsFilter.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package quote
+
+import (
+	"errors"
+	"strings"
+)
+
+// ValidationError indica um campo da cotação que não passou na validação.
+// Field usa o caminho do campo na QuoteRequest, ex.: "recipient.zipcode".
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// ValidationErrors reúne todas as violações encontradas em uma validação,
+// para que o cliente possa corrigir todos os campos de uma vez.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+func (e *ValidationErrors) Add(field, message string) {
+	*e = append(*e, &ValidationError{Field: field, Message: message})
+}
+
+// Merge adiciona as violações de err prefixando o caminho de cada campo.
+func (e *ValidationErrors) Merge(prefix string, err error) {
+	if err == nil {
+		return
+	}
+	var errs ValidationErrors
+	var validationErr *ValidationError
+	switch {
+	case errors.As(err, &errs):
+		for _, v := range errs {
+			e.Add(prefix+"."+v.Field, v.Message)
+		}
+	case errors.As(err, &validationErr):
+		e.Add(prefix+"."+validationErr.Field, validationErr.Message)
+	default:
+		e.Add(prefix, err.Error())
+	}
+}
+
+func (e ValidationErrors) ErrOrNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// UpstreamRejectedError indica que o provedor de frete recusou a cotação
+// por um problema nos dados enviados.
+type UpstreamRejectedError struct {
+	Provider string
+	Err      error
+}
+
+func (e *UpstreamRejectedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UpstreamRejectedError) Unwrap() error {
+	return e.Err
+}
+
+// UpstreamUnavailableError indica que o provedor de frete não respondeu,
+// excedeu o tempo limite ou está com o circuito aberto.
+type UpstreamUnavailableError struct {
+	Provider string
+	Err      error
+}
+
+func (e *UpstreamUnavailableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UpstreamUnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// PersistenceError indica uma falha ao gravar ou ler dados de cotação.
+type PersistenceError struct {
+	Err error
+}
+
+func (e *PersistenceError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PersistenceError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,119 @@
+package quote
+
+import "time"
+
+type MetricsGranularity string
+
+const (
+	MetricsByDay   MetricsGranularity = "day"
+	MetricsByWeek  MetricsGranularity = "week"
+	MetricsByMonth MetricsGranularity = "month"
+)
+
+// MetricsRegionGrouping agrupa as métricas pelo destino da cotação: UF ou
+// macrorregião (pela tabela de faixas de CEP) ou pelos primeiros dígitos do CEP.
+type MetricsRegionGrouping string
+
+const (
+	RegionByUF        MetricsRegionGrouping = "uf"
+	RegionByRegion    MetricsRegionGrouping = "region"
+	RegionByCEPPrefix MetricsRegionGrouping = "cep_prefix"
+
+	DefaultCEPPrefixLength = 1
+	MaxCEPPrefixLength     = 5
+)
+
+func (r MetricsRegionGrouping) IsValid() bool {
+	switch r {
+	case "", RegionByUF, RegionByRegion, RegionByCEPPrefix:
+		return true
+	}
+	return false
+}
+
+func (g MetricsGranularity) IsValid() bool {
+	switch g {
+	case "", MetricsByDay, MetricsByWeek, MetricsByMonth:
+		return true
+	}
+	return false
+}
+
+// MetricsFilter delimita as ofertas usadas nas métricas. Campos zerados não
+// filtram; LastQuotes considera só as N ofertas mais recentes que passaram
+// pelos demais filtros. Com GroupBy preenchido, Metrics.Series traz os
+// agregados por transportadora em cada período.
+type MetricsFilter struct {
+	LastQuotes int
+	From       time.Time
+	To         time.Time
+	Carrier    string
+	Service    string
+	GroupBy    MetricsGranularity
+
+	RegionBy        MetricsRegionGrouping
+	CEPPrefixLength int
+}
+
+func (f *MetricsFilter) Validate() error {
+	var errs ValidationErrors
+	if f.LastQuotes < 0 {
+		errs.Add("last_quotes", "last_quotes não pode ser negativo")
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && f.From.After(f.To) {
+		errs.Add("from", "data inicial deve ser anterior à data final")
+	}
+	if !f.GroupBy.IsValid() {
+		errs.Add("group_by", "group_by deve ser 'day', 'week' ou 'month'")
+	}
+	if !f.RegionBy.IsValid() {
+		errs.Add("region_by", "region_by deve ser 'uf', 'region' ou 'cep_prefix'")
+	}
+	if f.CEPPrefixLength < 0 || f.CEPPrefixLength > MaxCEPPrefixLength {
+		errs.Add("cep_prefix_length", "cep_prefix_length deve estar entre 1 e 5")
+	}
+	return errs.ErrOrNil()
+}
+
+// MetricsBucket são os agregados por transportadora de um período, que começa
+// em PeriodStart (UTC).
+type MetricsBucket struct {
+	PeriodStart time.Time
+	Carrier     []CarrierMetrics
+}
+
+// RegionCarrierMetrics são as médias de uma transportadora em uma região.
+type RegionCarrierMetrics struct {
+	Name            string
+	TotalOffer      int
+	AvgPrice        float64
+	AvgDeliveryTime float64
+}
+
+// RegionMetrics resume uma região de destino. Cheapest é a transportadora de
+// menor preço médio e Fastest a de menor prazo médio; empates são decididos
+// pelo outro critério.
+type RegionMetrics struct {
+	Region     string
+	TotalOffer int
+	Carrier    []RegionCarrierMetrics
+	Cheapest   *RegionCarrierMetrics
+	Fastest    *RegionCarrierMetrics
+}
+
+func NewRegionMetrics(region string, carriers []RegionCarrierMetrics) RegionMetrics {
+	metrics := RegionMetrics{Region: region, Carrier: carriers}
+	for i := range carriers {
+		c := &carriers[i]
+		metrics.TotalOffer += c.TotalOffer
+		if metrics.Cheapest == nil || c.AvgPrice < metrics.Cheapest.AvgPrice ||
+			(c.AvgPrice == metrics.Cheapest.AvgPrice && c.AvgDeliveryTime < metrics.Cheapest.AvgDeliveryTime) {
+			metrics.Cheapest = c
+		}
+		if metrics.Fastest == nil || c.AvgDeliveryTime < metrics.Fastest.AvgDeliveryTime ||
+			(c.AvgDeliveryTime == metrics.Fastest.AvgDeliveryTime && c.AvgPrice < metrics.Fastest.AvgPrice) {
+			metrics.Fastest = c
+		}
+	}
+	return metrics
+}
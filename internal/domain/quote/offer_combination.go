@@ -0,0 +1,80 @@
+package quote
+
+import "math"
+
+// DispatcherOffers agrupa as ofertas de um único expedidor (centro de distribuição).
+type DispatcherOffers struct {
+	WarehouseID string
+	Offers      []Offer
+}
+
+// OfferCombination é uma oferta escolhida por expedidor; o envio completo
+// custa a soma dos preços e chega quando a entrega mais lenta chegar.
+type OfferCombination struct {
+	Offers       []Offer
+	TotalPrice   float64
+	DeliveryTime int
+}
+
+// GroupOffersByWarehouse agrupa as ofertas na ordem dos expedidores da cotação.
+func GroupOffersByWarehouse(dispatchers []Dispatcher, offers []Offer) []DispatcherOffers {
+	groups := make([]DispatcherOffers, len(dispatchers))
+	index := make(map[string]int, len(dispatchers))
+	for i, d := range dispatchers {
+		groups[i].WarehouseID = d.WarehouseID
+		index[d.WarehouseID] = i
+	}
+	for _, offer := range offers {
+		if i, ok := index[offer.WarehouseID]; ok {
+			groups[i].Offers = append(groups[i].Offers, offer)
+		}
+	}
+	return groups
+}
+
+// CheapestCombination escolhe a oferta mais barata de cada expedidor,
+// desempatando pelo menor prazo. Retorna nil se algum expedidor ficou sem oferta.
+func CheapestCombination(groups []DispatcherOffers) *OfferCombination {
+	return combine(groups, func(a, b Offer) bool {
+		if a.FinalPrice != b.FinalPrice {
+			return a.FinalPrice < b.FinalPrice
+		}
+		return a.DeliveryTime < b.DeliveryTime
+	})
+}
+
+// FastestCombination escolhe a oferta de menor prazo de cada expedidor,
+// desempatando pelo menor preço. Retorna nil se algum expedidor ficou sem oferta.
+func FastestCombination(groups []DispatcherOffers) *OfferCombination {
+	return combine(groups, func(a, b Offer) bool {
+		if a.DeliveryTime != b.DeliveryTime {
+			return a.DeliveryTime < b.DeliveryTime
+		}
+		return a.FinalPrice < b.FinalPrice
+	})
+}
+
+func combine(groups []DispatcherOffers, better func(a, b Offer) bool) *OfferCombination {
+	if len(groups) == 0 {
+		return nil
+	}
+	var combination OfferCombination
+	for _, group := range groups {
+		if len(group.Offers) == 0 {
+			return nil
+		}
+		best := group.Offers[0]
+		for _, offer := range group.Offers[1:] {
+			if better(offer, best) {
+				best = offer
+			}
+		}
+		combination.Offers = append(combination.Offers, best)
+		combination.TotalPrice += best.FinalPrice
+		if best.DeliveryTime > combination.DeliveryTime {
+			combination.DeliveryTime = best.DeliveryTime
+		}
+	}
+	combination.TotalPrice = math.Round(combination.TotalPrice*100) / 100
+	return &combination
+}
@@ -1,17 +1,59 @@
 package quote
 
+import (
+	"context"
+	"time"
+)
+
 type SimulateQuoteOutPutPort interface {
-	Execute(quoteData QuoteRequest) ([]Offer, error)
+	Execute(ctx context.Context, quoteData QuoteRequest) ([]Offer, error)
+}
+
+// Simulation são as ofertas de uma simulação e de onde vieram. Quando Cached
+// é verdadeiro elas saíram do cache e UpstreamLatency é a latência da chamada
+// original ao provedor, não a da leitura do cache. Partial indica que algum
+// dos provedores consultados falhou e as ofertas estão incompletas.
+type Simulation struct {
+	Offers          []Offer
+	Cached          bool
+	Partial         bool
+	UpstreamLatency time.Duration
+}
+
+// SimulationOutPutPort é implementado por provedores que sabem dizer, além
+// das ofertas, se elas vieram do cache ou estão incompletas.
+type SimulationOutPutPort interface {
+	SimulateQuoteOutPutPort
+	Simulate(ctx context.Context, quoteData QuoteRequest) (*Simulation, error)
 }
 
 type SimulateInputPort interface {
-	Simulate(request QuoteRequest) ([]Offer, error)
+	Simulate(ctx context.Context, request QuoteRequest) (*Quote, error)
 }
 
 type MetricsOutputPort interface {
-	Execute(lastQuotes int) (*Metrics, error)
+	Execute(ctx context.Context, filter MetricsFilter) (*Metrics, error)
 }
 
 type MetricsInputPort interface {
-	GetMetrics(lastQuotes int) (*Metrics, error)
+	GetMetrics(ctx context.Context, filter MetricsFilter) (*Metrics, error)
+}
+
+type WarehouseOutputPort interface {
+	ListWarehouses(ctx context.Context) ([]Warehouse, error)
+}
+
+type WarehouseInputPort interface {
+	ResolveOrigin(ctx context.Context, warehouseID string, recipientZipcode int) (*Warehouse, error)
+}
+
+type QuoteHistoryOutputPort interface {
+	SaveQuote(ctx context.Context, quote *Quote) error
+	GetQuote(ctx context.Context, id string) (*Quote, error)
+	ListQuotes(ctx context.Context, filter QuoteFilter) (*QuotePage, error)
+}
+
+type QuoteHistoryInputPort interface {
+	GetQuote(ctx context.Context, id string) (*Quote, error)
+	ListQuotes(ctx context.Context, filter QuoteFilter) (*QuotePage, error)
 }
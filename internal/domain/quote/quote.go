@@ -1,7 +1,6 @@
 package quote
 
 import (
-	"errors"
 	"fmt"
 	"regexp"
 )
@@ -13,16 +12,17 @@ type Shipper struct {
 }
 
 func (s *Shipper) Validate() error {
+	var errs ValidationErrors
 	if !isValidCNPJ(s.RegisteredNumber) {
-		return errors.New("CNPJ do remetente inválido")
+		errs.Add("registered_number", "CNPJ do remetente inválido")
 	}
 	if len(s.Token) != 32 {
-		return errors.New("token deve ter 32 caracteres")
+		errs.Add("token", "token deve ter 32 caracteres")
 	}
 	if s.PlatformCode == "" {
-		return errors.New("código da plataforma é obrigatório")
+		errs.Add("platform_code", "código da plataforma é obrigatório")
 	}
-	return nil
+	return errs.ErrOrNil()
 }
 
 type Recipient struct {
@@ -33,25 +33,27 @@ type Recipient struct {
 }
 
 func (r *Recipient) Validate() error {
+	var errs ValidationErrors
 	if r.Type != 0 && r.Type != 1 {
-		return errors.New("tipo de destinatário deve ser 'PF(0)' ou 'PJ(1)'")
+		errs.Add("type", "tipo de destinatário deve ser 'PF(0)' ou 'PJ(1)'")
 	}
 	if r.Country != "BRA" {
-		return errors.New("país deve ser 'BRA'")
+		errs.Add("country", "país deve ser 'BRA'")
 	}
 	if !isValidCEP(r.Zipcode) {
-		return errors.New("CEP inválido")
+		errs.Add("zipcode", "CEP inválido")
 	}
 	if (r.Type == 1 && r.RegisteredNumber != "") && !isValidCNPJ(r.RegisteredNumber) {
-		return errors.New("CNPJ do destinatário inválido")
+		errs.Add("registered_number", "CNPJ do destinatário inválido")
 	}
 	if (r.Type == 0 && r.RegisteredNumber != "") && !isValidCPF(r.RegisteredNumber) {
-		return errors.New("CPF do destinatário inválido")
+		errs.Add("registered_number", "CPF do destinatário inválido")
 	}
-	return nil
+	return errs.ErrOrNil()
 }
 
 type Volume struct {
+	SKU           string
 	Category      string
 	Amount        int
 	UnitaryWeight float64
@@ -62,46 +64,53 @@ type Volume struct {
 }
 
 func (v *Volume) Validate() error {
+	var errs ValidationErrors
 	if v.Category == "" {
-		return errors.New("categoria do volume é obrigatória")
+		errs.Add("category", "categoria do volume é obrigatória")
 	}
 	if v.Amount <= 0 {
-		return errors.New("quantidade deve ser maior que zero")
+		errs.Add("amount", "quantidade deve ser maior que zero")
 	}
 	if v.UnitaryWeight <= 0 {
-		return errors.New("peso unitário deve ser maior que zero")
+		errs.Add("unitary_weight", "peso unitário deve ser maior que zero")
 	}
 	if v.UnitaryPrice < 0 {
-		return errors.New("preço unitário não pode ser negativo")
+		errs.Add("unitary_price", "preço unitário não pode ser negativo")
 	}
-	if v.Height <= 0 || v.Width <= 0 || v.Length <= 0 {
-		return errors.New("dimensões devem ser maiores que zero")
+	if v.Height <= 0 {
+		errs.Add("height", "altura deve ser maior que zero")
 	}
-	return nil
+	if v.Width <= 0 {
+		errs.Add("width", "largura deve ser maior que zero")
+	}
+	if v.Length <= 0 {
+		errs.Add("length", "comprimento deve ser maior que zero")
+	}
+	return errs.ErrOrNil()
 }
 
 type Dispatcher struct {
+	WarehouseID      string
 	RegisteredNumber string
 	Zipcode          int
 	Volumes          []Volume
 }
 
 func (d *Dispatcher) Validate() error {
+	var errs ValidationErrors
 	if !isValidCNPJ(d.RegisteredNumber) {
-		return errors.New("CNPJ do expedidor inválido")
+		errs.Add("registered_number", "CNPJ do expedidor inválido")
 	}
 	if !isValidCEP(d.Zipcode) {
-		return errors.New("CEP do expedidor inválido")
+		errs.Add("zipcode", "CEP do expedidor inválido")
 	}
 	if len(d.Volumes) == 0 {
-		return errors.New("pelo menos um volume é obrigatório")
+		errs.Add("volumes", "pelo menos um volume é obrigatório")
 	}
-	for _, volume := range d.Volumes {
-		if err := volume.Validate(); err != nil {
-			return err
-		}
+	for i, volume := range d.Volumes {
+		errs.Merge(fmt.Sprintf("volumes[%d]", i), volume.Validate())
 	}
-	return nil
+	return errs.ErrOrNil()
 }
 
 type QuoteRequest struct {
@@ -110,33 +119,28 @@ type QuoteRequest struct {
 	Dispatchers []Dispatcher
 }
 
-func (q *QuoteRequest) Validate() error {
-
-	if err := q.Shipper.Validate(); err != nil {
-		return err
-	}
-	if err := q.Recipient.Validate(); err != nil {
-		return err
+// Normalize remove a formatação dos CNPJs/CPFs para que sejam enviados aos
+// provedores apenas com dígitos e letras.
+func (q *QuoteRequest) Normalize() {
+	q.Shipper.RegisteredNumber = NormalizeRegisteredNumber(q.Shipper.RegisteredNumber)
+	q.Recipient.RegisteredNumber = NormalizeRegisteredNumber(q.Recipient.RegisteredNumber)
+	q.Dispatchers = append([]Dispatcher(nil), q.Dispatchers...)
+	for i := range q.Dispatchers {
+		q.Dispatchers[i].RegisteredNumber = NormalizeRegisteredNumber(q.Dispatchers[i].RegisteredNumber)
 	}
+}
+
+func (q *QuoteRequest) Validate() error {
+	var errs ValidationErrors
+	errs.Merge("shipper", q.Shipper.Validate())
+	errs.Merge("recipient", q.Recipient.Validate())
 	if len(q.Dispatchers) == 0 {
-		return errors.New("pelo menos um expedidor é obrigatório")
+		errs.Add("dispatchers", "pelo menos um expedidor é obrigatório")
 	}
-	for _, dispatcher := range q.Dispatchers {
-		if err := dispatcher.Validate(); err != nil {
-			return err
-		}
+	for i, dispatcher := range q.Dispatchers {
+		errs.Merge(fmt.Sprintf("dispatchers[%d]", i), dispatcher.Validate())
 	}
-	return nil
-}
-
-func isValidCNPJ(cnpj string) bool {
-	matched, _ := regexp.MatchString(`^\d{14}$`, cnpj)
-	return matched
-}
-
-func isValidCPF(cpf string) bool {
-	matched, _ := regexp.MatchString(`^\d{11}$`, cpf)
-	return matched
+	return errs.ErrOrNil()
 }
 
 func isValidCEP(cep int) bool {
@@ -151,6 +155,8 @@ type Offer struct {
 	Carrier      string
 	Service      string
 	DeliveryTime int
+	Source       string
+	WarehouseID  string
 }
 
 type CarrierMetrics struct {
@@ -160,6 +166,16 @@ type CarrierMetrics struct {
 	MinPrice   float64
 	TotalPrice float64
 	TotalOffer int
+
+	P50Price      float64
+	P90Price      float64
+	P99Price      float64
+	StdDevPrice   float64
+	AvgPricePerKg float64
+
+	AvgDeliveryTime float64
+	MinDeliveryTime int
+	MaxDeliveryTime int
 }
 
 type Metrics struct {
@@ -169,4 +185,6 @@ type Metrics struct {
 	GeneralMaxPrice       float64
 	GeneralMinCarrierName string
 	GeneralMaxCarrierName string
+	Series                []MetricsBucket
+	Regions               []RegionMetrics
 }
@@ -0,0 +1,133 @@
+package quote
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrQuoteNotFound = errors.New("cotação não encontrada")
+
+type QuoteStatus string
+
+const (
+	QuoteStatusCompleted QuoteStatus = "completed"
+	QuoteStatusNoOffers  QuoteStatus = "no_offers"
+	QuoteStatusFailed    QuoteStatus = "failed"
+)
+
+// Quote é o registro de uma simulação: a requisição enviada aos provedores,
+// as ofertas recebidas e quanto tempo o upstream levou para responder.
+// RequestID é o X-Request-ID da requisição HTTP que a gerou, quando houver.
+// Cached marca as cotações servidas do cache: as ofertas repetem as de uma
+// cotação anterior e ficam fora das métricas.
+type Quote struct {
+	ID              string
+	RequestID       string
+	Request         QuoteRequest
+	Offers          []Offer
+	Status          QuoteStatus
+	Cached          bool
+	UpstreamLatency time.Duration
+	CreatedAt       time.Time
+}
+
+const (
+	DefaultQuotePageSize = 20
+	MaxQuotePageSize     = 100
+)
+
+// QuoteCursor aponta para a última cotação de uma página; a próxima página
+// começa na cotação imediatamente mais antiga.
+type QuoteCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+func (c QuoteCursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeQuoteCursor(cursor string) (*QuoteCursor, error) {
+	invalid := &ValidationError{Field: "cursor", Message: "cursor de paginação inválido"}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+	createdAt, id, found := strings.Cut(string(raw), "|")
+	if !found || id == "" {
+		return nil, invalid
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, invalid
+	}
+	return &QuoteCursor{CreatedAt: t, ID: id}, nil
+}
+
+// QuoteFilter filtra o histórico de cotações. Campos zerados não filtram;
+// Carrier e a faixa de preço precisam ser atendidos pela mesma oferta.
+type QuoteFilter struct {
+	From             time.Time
+	To               time.Time
+	RecipientZipcode int
+	Carrier          string
+	MinPrice         *float64
+	MaxPrice         *float64
+	After            *QuoteCursor
+	Limit            int
+}
+
+type QuotePage struct {
+	Quotes     []Quote
+	NextCursor string
+}
+
+func NewQuoteID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func (q *Quote) TotalWeight() float64 {
+	total := 0.0
+	for _, d := range q.Request.Dispatchers {
+		total += d.TotalWeight()
+	}
+	return total
+}
+
+func (q *Quote) DeclaredValue() float64 {
+	total := 0.0
+	for _, d := range q.Request.Dispatchers {
+		for _, v := range d.Volumes {
+			total += float64(v.Amount) * v.UnitaryPrice
+		}
+	}
+	return total
+}
+
+// OfferWeight é o peso transportado por uma oferta: o do expedidor de onde
+// ela sai ou, se a oferta não indica o expedidor, o da cotação inteira.
+func (q *Quote) OfferWeight(offer Offer) float64 {
+	for i := range q.Request.Dispatchers {
+		if q.Request.Dispatchers[i].WarehouseID == offer.WarehouseID {
+			return q.Request.Dispatchers[i].TotalWeight()
+		}
+	}
+	return q.TotalWeight()
+}
+
+func (d *Dispatcher) TotalWeight() float64 {
+	total := 0.0
+	for _, v := range d.Volumes {
+		total += float64(v.Amount) * v.UnitaryWeight
+	}
+	return total
+}
@@ -1,26 +1,129 @@
 package quote
 
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
 type QuoteService struct {
 	SmltPort    SimulateQuoteOutPutPort
 	MetricsPort MetricsOutputPort
+	HistoryPort QuoteHistoryOutputPort
+	logger      *slog.Logger
 }
 
-func NewQuoteService(portSmlt SimulateQuoteOutPutPort, portMetrics MetricsOutputPort) *QuoteService {
+// NewQuoteService recebe o logger da aplicação, para que os avisos do
+// serviço saiam com os atributos da requisição e a mesma máscara de segredos;
+// sem ele usa o slog.Default.
+func NewQuoteService(portSmlt SimulateQuoteOutPutPort, portMetrics MetricsOutputPort, portHistory QuoteHistoryOutputPort, logger *slog.Logger) *QuoteService {
+	if logger == nil {
+		logger = slog.Default()
+	}
 	return &QuoteService{
 		SmltPort:    portSmlt,
 		MetricsPort: portMetrics,
+		HistoryPort: portHistory,
+		logger:      logger,
 	}
 }
 
-func (qs *QuoteService) Simulate(quote QuoteRequest) ([]Offer, error) {
+func (qs *QuoteService) Simulate(ctx context.Context, quote QuoteRequest) (*Quote, error) {
 
+	quote.Normalize()
 	if err := quote.Validate(); err != nil {
 		return nil, err
 	}
-	return qs.SmltPort.Execute(quote)
 
+	record := Quote{
+		ID:        NewQuoteID(),
+		Request:   quote,
+		CreatedAt: time.Now(),
+	}
+	start := time.Now()
+	simulation, err := qs.simulate(ctx, quote)
+	if err != nil {
+		record.UpstreamLatency = time.Since(start)
+		if ctx.Err() == nil {
+			record.Status = QuoteStatusFailed
+			if saveErr := qs.HistoryPort.SaveQuote(ctx, &record); saveErr != nil {
+				qs.logger.ErrorContext(ctx, "não foi possível salvar a cotação com falha", slog.String("quote_id", record.ID), slog.String("error", saveErr.Error()))
+			}
+		}
+		return nil, err
+	}
+
+	record.Offers = simulation.Offers
+	record.Cached = simulation.Cached
+	record.UpstreamLatency = simulation.UpstreamLatency
+	record.Status = QuoteStatusCompleted
+	if len(record.Offers) == 0 {
+		record.Status = QuoteStatusNoOffers
+	}
+	if err = qs.HistoryPort.SaveQuote(ctx, &record); err != nil {
+		return nil, &PersistenceError{Err: err}
+	}
+	return &record, nil
+
+}
+
+func (qs *QuoteService) GetMetrics(ctx context.Context, filter MetricsFilter) (*Metrics, error) {
+	if filter.RegionBy == RegionByCEPPrefix && filter.CEPPrefixLength == 0 {
+		filter.CEPPrefixLength = DefaultCEPPrefixLength
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return qs.MetricsPort.Execute(ctx, filter)
+}
+
+func (qs *QuoteService) GetQuote(ctx context.Context, id string) (*Quote, error) {
+	quote, err := qs.HistoryPort.GetQuote(ctx, id)
+	if errors.Is(err, ErrQuoteNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, &PersistenceError{Err: err}
+	}
+	return quote, nil
+}
+
+func (qs *QuoteService) ListQuotes(ctx context.Context, filter QuoteFilter) (*QuotePage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultQuotePageSize
+	}
+	if filter.Limit > MaxQuotePageSize {
+		filter.Limit = MaxQuotePageSize
+	}
+	var errs ValidationErrors
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.From.After(filter.To) {
+		errs.Add("from", "data inicial deve ser anterior à data final")
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		errs.Add("min_price", "preço mínimo deve ser menor que o preço máximo")
+	}
+	if err := errs.ErrOrNil(); err != nil {
+		return nil, err
+	}
+
+	page, err := qs.HistoryPort.ListQuotes(ctx, filter)
+	if err != nil {
+		return nil, &PersistenceError{Err: err}
+	}
+	return page, nil
 }
 
-func (qs *QuoteService) GetMetrics(lastQuotes int) (*Metrics, error) {
-	return qs.MetricsPort.Execute(lastQuotes)
+// simulate pede as ofertas ao provedor. Se ele souber dizer que serviu do
+// cache, a cotação é marcada e guarda a latência original do provedor.
+func (qs *QuoteService) simulate(ctx context.Context, quote QuoteRequest) (*Simulation, error) {
+	if port, ok := qs.SmltPort.(SimulationOutPutPort); ok {
+		return port.Simulate(ctx, quote)
+	}
+	start := time.Now()
+	offers, err := qs.SmltPort.Execute(ctx, quote)
+	if err != nil {
+		return nil, err
+	}
+	return &Simulation{Offers: offers, UpstreamLatency: time.Since(start)}, nil
 }
@@ -1,35 +1,67 @@
 package quote
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"log/slog"
 	"testing"
+	"time"
 )
 
 type MockSimulatePort struct {
 	mock.Mock
 }
 
-func (m *MockSimulatePort) Execute(req QuoteRequest) ([]Offer, error) {
+func (m *MockSimulatePort) Execute(ctx context.Context, req QuoteRequest) ([]Offer, error) {
 	args := m.Called(req)
 	return args.Get(0).([]Offer), args.Error(1)
 }
 
+type MockSimulationPort struct {
+	MockSimulatePort
+}
+
+func (m *MockSimulationPort) Simulate(ctx context.Context, req QuoteRequest) (*Simulation, error) {
+	args := m.Called(req)
+	return args.Get(0).(*Simulation), args.Error(1)
+}
+
 type MockMetricsPort struct {
 	mock.Mock
 }
 
-func (m *MockMetricsPort) Execute(lastQuotes int) (*Metrics, error) {
-	args := m.Called(lastQuotes)
+func (m *MockMetricsPort) Execute(ctx context.Context, filter MetricsFilter) (*Metrics, error) {
+	args := m.Called(filter)
 	return args.Get(0).(*Metrics), args.Error(1)
 }
 
+type MockHistoryPort struct {
+	mock.Mock
+}
+
+func (m *MockHistoryPort) SaveQuote(ctx context.Context, quote *Quote) error {
+	args := m.Called(quote)
+	return args.Error(0)
+}
+
+func (m *MockHistoryPort) GetQuote(ctx context.Context, id string) (*Quote, error) {
+	args := m.Called(id)
+	return args.Get(0).(*Quote), args.Error(1)
+}
+
+func (m *MockHistoryPort) ListQuotes(ctx context.Context, filter QuoteFilter) (*QuotePage, error) {
+	args := m.Called(filter)
+	return args.Get(0).(*QuotePage), args.Error(1)
+}
+
 func ValidRequest() QuoteRequest {
 	validReq := QuoteRequest{
 		Shipper: Shipper{
 			RegisteredNumber: "25438296000158",
-			Token:            "1d52a9b6b78cf07b08586152459a5c90",
+			Token:            "token-de-teste-com-32-caracteres",
 			PlatformCode:     "5AKVkHqCn",
 		},
 		Recipient: Recipient{
@@ -80,43 +112,184 @@ func InvalidRequest() QuoteRequest {
 func TestSimulateQuote_Success(t *testing.T) {
 
 	mockSimulate := new(MockSimulatePort)
-	qs := NewQuoteService(mockSimulate, nil)
+	mockHistory := new(MockHistoryPort)
+	qs := NewQuoteService(mockSimulate, nil, mockHistory, nil)
 	validReq := ValidRequest()
+	mockHistory.On("SaveQuote", mock.MatchedBy(func(q *Quote) bool {
+		return q.ID != "" && q.Status == QuoteStatusCompleted && len(q.Offers) == 1
+	})).Return(nil)
 
 	mockSimulate.On("Execute", validReq).Return([]Offer{
 		{Carrier: "Correios", FinalPrice: 50.99, DeliveryTime: 1, Service: "SEDEX"},
 	}, nil)
 
-	offers, err := qs.Simulate(validReq)
+	simulated, err := qs.Simulate(context.Background(), validReq)
 
 	assert.NoError(t, err)
-	assert.Equal(t, 1, len(offers))
-	assert.Equal(t, "Correios", offers[0].Carrier)
-	assert.Equal(t, "SEDEX", offers[0].Service)
+	assert.NotEmpty(t, simulated.ID)
+	assert.Equal(t, 1, len(simulated.Offers))
+	assert.Equal(t, "Correios", simulated.Offers[0].Carrier)
+	assert.Equal(t, "SEDEX", simulated.Offers[0].Service)
 	mockSimulate.AssertExpectations(t)
+	mockHistory.AssertExpectations(t)
+}
+
+func TestSimulateQuote_CachedKeepsOriginalLatency(t *testing.T) {
+	mockSimulate := new(MockSimulationPort)
+	mockHistory := new(MockHistoryPort)
+	qs := NewQuoteService(mockSimulate, nil, mockHistory, nil)
+	validReq := ValidRequest()
+
+	mockSimulate.On("Simulate", validReq).Return(&Simulation{
+		Offers:          []Offer{{Carrier: "Correios", FinalPrice: 50.99, DeliveryTime: 1, Service: "SEDEX"}},
+		Cached:          true,
+		UpstreamLatency: 800 * time.Millisecond,
+	}, nil)
+	mockHistory.On("SaveQuote", mock.MatchedBy(func(q *Quote) bool {
+		return q.Cached && q.UpstreamLatency == 800*time.Millisecond && q.Status == QuoteStatusCompleted
+	})).Return(nil)
+
+	simulated, err := qs.Simulate(context.Background(), validReq)
+
+	assert.NoError(t, err)
+	assert.True(t, simulated.Cached)
+	mockSimulate.AssertNotCalled(t, "Execute", mock.Anything)
+	mockHistory.AssertExpectations(t)
+}
+
+func TestSimulateQuote_PersistenceError(t *testing.T) {
+	mockSimulate := new(MockSimulatePort)
+	mockHistory := new(MockHistoryPort)
+	qs := NewQuoteService(mockSimulate, nil, mockHistory, nil)
+	validReq := ValidRequest()
+
+	mockSimulate.On("Execute", validReq).Return([]Offer{
+		{Carrier: "Correios", FinalPrice: 50.99, DeliveryTime: 1, Service: "SEDEX"},
+	}, nil)
+	mockHistory.On("SaveQuote", mock.Anything).Return(errors.New("Error ao salvar no banco"))
+
+	_, err := qs.Simulate(context.Background(), validReq)
+
+	var persistenceErr *PersistenceError
+	assert.ErrorAs(t, err, &persistenceErr)
+	assert.Equal(t, "Error ao salvar no banco", err.Error())
+}
+
+func TestSimulateQuote_UpstreamErrorIsRecorded(t *testing.T) {
+	mockSimulate := new(MockSimulatePort)
+	mockHistory := new(MockHistoryPort)
+	qs := NewQuoteService(mockSimulate, nil, mockHistory, nil)
+	validReq := ValidRequest()
+
+	upstreamErr := &UpstreamUnavailableError{Provider: "frete_rapido", Err: errors.New("timeout")}
+	mockSimulate.On("Execute", validReq).Return([]Offer(nil), upstreamErr)
+	mockHistory.On("SaveQuote", mock.MatchedBy(func(q *Quote) bool {
+		return q.Status == QuoteStatusFailed
+	})).Return(nil)
+
+	_, err := qs.Simulate(context.Background(), validReq)
+
+	assert.ErrorIs(t, err, upstreamErr)
+	mockHistory.AssertExpectations(t)
+}
+
+func TestSimulateQuote_LogsFailedSaveWithInjectedLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	mockSimulate := new(MockSimulatePort)
+	mockHistory := new(MockHistoryPort)
+	qs := NewQuoteService(mockSimulate, nil, mockHistory, logger)
+	validReq := ValidRequest()
+
+	mockSimulate.On("Execute", validReq).Return([]Offer(nil), errors.New("timeout"))
+	mockHistory.On("SaveQuote", mock.Anything).Return(errors.New("banco fora do ar"))
+
+	_, err := qs.Simulate(context.Background(), validReq)
+
+	assert.EqualError(t, err, "timeout")
+	assert.Contains(t, buf.String(), "não foi possível salvar a cotação com falha")
+	assert.Contains(t, buf.String(), "banco fora do ar")
 }
 
 func TestSimulateQuote_ValidationError(t *testing.T) {
-	qs := NewQuoteService(nil, nil) // Porta não será usada
+	qs := NewQuoteService(nil, nil, nil, nil) // Porta não será usada
 
 	invalidReq := InvalidRequest()
 
-	_, err := qs.Simulate(invalidReq)
+	_, err := qs.Simulate(context.Background(), invalidReq)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "CNPJ do remetente inválido")
 }
 
+func TestGetQuote_NotFound(t *testing.T) {
+	mockHistory := new(MockHistoryPort)
+	qs := NewQuoteService(nil, nil, mockHistory, nil)
+	mockHistory.On("GetQuote", "abc").Return((*Quote)(nil), ErrQuoteNotFound)
+
+	_, err := qs.GetQuote(context.Background(), "abc")
+
+	assert.ErrorIs(t, err, ErrQuoteNotFound)
+	var persistenceErr *PersistenceError
+	assert.False(t, errors.As(err, &persistenceErr))
+}
+
+func TestListQuotes_DefaultsAndCapsLimit(t *testing.T) {
+	mockHistory := new(MockHistoryPort)
+	qs := NewQuoteService(nil, nil, mockHistory, nil)
+	mockHistory.On("ListQuotes", QuoteFilter{Limit: DefaultQuotePageSize}).Return(&QuotePage{}, nil)
+	mockHistory.On("ListQuotes", QuoteFilter{Limit: MaxQuotePageSize}).Return(&QuotePage{}, nil)
+
+	_, err := qs.ListQuotes(context.Background(), QuoteFilter{})
+	assert.NoError(t, err)
+	_, err = qs.ListQuotes(context.Background(), QuoteFilter{Limit: 1000})
+	assert.NoError(t, err)
+	mockHistory.AssertExpectations(t)
+}
+
+func TestListQuotes_InvalidRanges(t *testing.T) {
+	qs := NewQuoteService(nil, nil, nil, nil)
+	minPrice, maxPrice := 100.0, 10.0
+
+	_, err := qs.ListQuotes(context.Background(), QuoteFilter{
+		From:     time.Date(2025, 4, 10, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+		MinPrice: &minPrice,
+		MaxPrice: &maxPrice,
+	})
+
+	var validationErrs ValidationErrors
+	assert.ErrorAs(t, err, &validationErrs)
+	assert.Len(t, validationErrs, 2)
+	assert.Equal(t, "from", validationErrs[0].Field)
+	assert.Equal(t, "min_price", validationErrs[1].Field)
+}
+
+func TestQuoteCursor_RoundTrip(t *testing.T) {
+	cursor := QuoteCursor{CreatedAt: time.Date(2025, 4, 12, 9, 30, 0, 123456000, time.UTC), ID: "f00d"}
+
+	decoded, err := DecodeQuoteCursor(cursor.Encode())
+
+	assert.NoError(t, err)
+	assert.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt))
+	assert.Equal(t, "f00d", decoded.ID)
+
+	_, err = DecodeQuoteCursor("não-é-um-cursor")
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "cursor", validationErr.Field)
+}
+
 func TestGetQuoteMetrics_Success(t *testing.T) {
 	mockMetrics := new(MockMetricsPort)
-	qs := NewQuoteService(nil, mockMetrics)
+	qs := NewQuoteService(nil, mockMetrics, nil, nil)
 	metricsCarrier := []CarrierMetrics{{Name: "Correios", AvgPrice: 50.99, MaxPrice: 50.99, MinPrice: 50.99, TotalPrice: 50.99 * 3, TotalOffer: 3}}
 	expectedMetrics := &Metrics{
 		Carrier: metricsCarrier, GeneralMaxCarrierName: "Correios", GeneralMinCarrierName: "Correios", GeneralAvgPrice: 50.99, GeneralMaxPrice: 50.99, GeneralMinPrice: 50.99}
 
-	mockMetrics.On("Execute", 3).Return(expectedMetrics, nil)
+	mockMetrics.On("Execute", MetricsFilter{LastQuotes: 3}).Return(expectedMetrics, nil)
 
-	metrics, err := qs.GetMetrics(3)
+	metrics, err := qs.GetMetrics(context.Background(), MetricsFilter{LastQuotes: 3})
 
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(metrics.Carrier))
@@ -127,13 +300,32 @@ func TestGetQuoteMetrics_Success(t *testing.T) {
 
 func TestGetQuoteMetrics_Error(t *testing.T) {
 	mockMetrics := new(MockMetricsPort)
-	qs := NewQuoteService(nil, mockMetrics)
+	qs := NewQuoteService(nil, mockMetrics, nil, nil)
 
-	mockMetrics.On("Execute", 5).Return(&Metrics{}, errors.New("falha no banco"))
+	mockMetrics.On("Execute", MetricsFilter{LastQuotes: 5}).Return(&Metrics{}, errors.New("falha no banco"))
 
-	_, err := qs.GetMetrics(5)
+	_, err := qs.GetMetrics(context.Background(), MetricsFilter{LastQuotes: 5})
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "falha no banco")
 
 }
+
+func TestGetQuoteMetrics_InvalidFilter(t *testing.T) {
+	qs := NewQuoteService(nil, nil, nil, nil) // Porta não será usada
+
+	_, err := qs.GetMetrics(context.Background(), MetricsFilter{
+		LastQuotes: -1,
+		From:       time.Date(2025, 4, 10, 0, 0, 0, 0, time.UTC),
+		To:         time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+		GroupBy:    "year",
+	})
+
+	var validationErrs ValidationErrors
+	assert.ErrorAs(t, err, &validationErrs)
+	fields := make([]string, len(validationErrs))
+	for i, v := range validationErrs {
+		fields[i] = v.Field
+	}
+	assert.Equal(t, []string{"last_quotes", "from", "group_by"}, fields)
+}
@@ -0,0 +1,85 @@
+package quote
+
+import (
+	"strings"
+)
+
+// NormalizeRegisteredNumber remove a pontuação de um CNPJ/CPF formatado
+// (ex.: 25.438.296/0001-58) e deixa as letras do CNPJ alfanumérico em maiúsculo.
+func NormalizeRegisteredNumber(number string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(number) {
+		switch r {
+		case '.', '/', '-', ' ':
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToUpper(b.String())
+}
+
+// isValidCNPJ aceita o CNPJ numérico e o alfanumérico: os 12 primeiros
+// caracteres podem ser 0-9 ou A-Z e os 2 últimos são dígitos verificadores
+// calculados com o valor ASCII do caractere menos 48.
+func isValidCNPJ(cnpj string) bool {
+	cnpj = NormalizeRegisteredNumber(cnpj)
+	if len(cnpj) != 14 || isRepeatedSequence(cnpj) {
+		return false
+	}
+	values := make([]int, 14)
+	for i := 0; i < 14; i++ {
+		c := cnpj[i]
+		switch {
+		case c >= '0' && c <= '9':
+		case i < 12 && c >= 'A' && c <= 'Z':
+		default:
+			return false
+		}
+		values[i] = int(c) - '0'
+	}
+	weights := []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}
+	first := cnpjCheckDigit(values[:12], weights[1:])
+	second := cnpjCheckDigit(append(values[:12:12], first), weights)
+	return values[12] == first && values[13] == second
+}
+
+func cnpjCheckDigit(values, weights []int) int {
+	sum := 0
+	for i, v := range values {
+		sum += v * weights[i]
+	}
+	rest := sum % 11
+	if rest < 2 {
+		return 0
+	}
+	return 11 - rest
+}
+
+func isValidCPF(cpf string) bool {
+	cpf = NormalizeRegisteredNumber(cpf)
+	if len(cpf) != 11 || isRepeatedSequence(cpf) {
+		return false
+	}
+	digits := make([]int, 11)
+	for i := 0; i < 11; i++ {
+		if cpf[i] < '0' || cpf[i] > '9' {
+			return false
+		}
+		digits[i] = int(cpf[i] - '0')
+	}
+	return digits[9] == cpfCheckDigit(digits[:9]) && digits[10] == cpfCheckDigit(digits[:10])
+}
+
+func cpfCheckDigit(digits []int) int {
+	sum := 0
+	weight := len(digits) + 1
+	for _, d := range digits {
+		sum += d * weight
+		weight--
+	}
+	return sum * 10 % 11 % 10
+}
+
+func isRepeatedSequence(number string) bool {
+	return strings.Count(number, number[:1]) == len(number)
+}
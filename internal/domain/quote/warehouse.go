@@ -0,0 +1,64 @@
+package quote
+
+import "errors"
+
+var ErrNoActiveWarehouse = errors.New("nenhum centro de distribuição ativo cadastrado")
+
+type Warehouse struct {
+	ID               string
+	Name             string
+	RegisteredNumber string
+	Zipcode          int
+	Active           bool
+}
+
+// Validate confere o cadastro do centro de distribuição, que vira o expedidor
+// de todas as cotações que saem dele.
+func (w Warehouse) Validate() error {
+	var errs ValidationErrors
+	if w.ID == "" {
+		errs.Add("id", "id do centro de distribuição é obrigatório")
+	}
+	if !isValidCNPJ(w.RegisteredNumber) {
+		errs.Add("registered_number", "CNPJ do centro de distribuição inválido")
+	}
+	if !isValidCEP(w.Zipcode) {
+		errs.Add("zipcode", "CEP inválido")
+	}
+	return errs.ErrOrNil()
+}
+
+func (w Warehouse) ToDispatcher(volumes []Volume) Dispatcher {
+	return Dispatcher{
+		WarehouseID:      w.ID,
+		RegisteredNumber: w.RegisteredNumber,
+		Zipcode:          w.Zipcode,
+		Volumes:          volumes,
+	}
+}
+
+// NearestWarehouse escolhe o centro de distribuição ativo com o CEP mais
+// próximo do destino. Os CEPs são distribuídos por região (o primeiro dígito
+// é a região postal e os seguintes subdividem estado e cidade), então a
+// diferença numérica é uma aproximação razoável da distância.
+func NearestWarehouse(warehouses []Warehouse, recipientZipcode int) (*Warehouse, error) {
+	var nearest *Warehouse
+	bestDistance := 0
+	for i := range warehouses {
+		if !warehouses[i].Active {
+			continue
+		}
+		distance := warehouses[i].Zipcode - recipientZipcode
+		if distance < 0 {
+			distance = -distance
+		}
+		if nearest == nil || distance < bestDistance {
+			nearest = &warehouses[i]
+			bestDistance = distance
+		}
+	}
+	if nearest == nil {
+		return nil, ErrNoActiveWarehouse
+	}
+	return nearest, nil
+}
@@ -0,0 +1,32 @@
+package quote
+
+import "context"
+
+type WarehouseService struct {
+	WarehousePort WarehouseOutputPort
+}
+
+func NewWarehouseService(portWarehouse WarehouseOutputPort) *WarehouseService {
+	return &WarehouseService{
+		WarehousePort: portWarehouse,
+	}
+}
+
+// ResolveOrigin retorna o centro de distribuição informado pelo cliente ou,
+// quando warehouseID é vazio, o ativo mais próximo do CEP de destino.
+func (ws *WarehouseService) ResolveOrigin(ctx context.Context, warehouseID string, recipientZipcode int) (*Warehouse, error) {
+	warehouses, err := ws.WarehousePort.ListWarehouses(ctx)
+	if err != nil {
+		return nil, &PersistenceError{Err: err}
+	}
+
+	if warehouseID == "" {
+		return NearestWarehouse(warehouses, recipientZipcode)
+	}
+	for i := range warehouses {
+		if warehouses[i].ID == warehouseID && warehouses[i].Active {
+			return &warehouses[i], nil
+		}
+	}
+	return nil, &ValidationError{Field: "origin.warehouse_id", Message: "centro de distribuição não encontrado ou inativo"}
+}
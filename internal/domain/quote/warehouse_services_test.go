@@ -0,0 +1,87 @@
+package quote
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+type MockWarehousePort struct {
+	mock.Mock
+}
+
+func (m *MockWarehousePort) ListWarehouses(ctx context.Context) ([]Warehouse, error) {
+	args := m.Called()
+	return args.Get(0).([]Warehouse), args.Error(1)
+}
+
+func Warehouses() []Warehouse {
+	return []Warehouse{
+		{ID: "sp-01", Name: "CD São Paulo", RegisteredNumber: "25438296000158", Zipcode: 1311000, Active: true},
+		{ID: "se-01", Name: "CD Aracaju", RegisteredNumber: "25438296000158", Zipcode: 49157021, Active: true},
+		{ID: "rs-01", Name: "CD Porto Alegre", RegisteredNumber: "25438296000158", Zipcode: 90010000, Active: false},
+	}
+}
+
+func TestResolveOrigin_ByID(t *testing.T) {
+	mockWarehouse := new(MockWarehousePort)
+	mockWarehouse.On("ListWarehouses").Return(Warehouses(), nil)
+	ws := NewWarehouseService(mockWarehouse)
+
+	warehouse, err := ws.ResolveOrigin(context.Background(), "se-01", 1311000)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "se-01", warehouse.ID)
+}
+
+func TestResolveOrigin_Nearest(t *testing.T) {
+	mockWarehouse := new(MockWarehousePort)
+	mockWarehouse.On("ListWarehouses").Return(Warehouses(), nil)
+	ws := NewWarehouseService(mockWarehouse)
+
+	warehouse, err := ws.ResolveOrigin(context.Background(), "", 49160000)
+	assert.NoError(t, err)
+	assert.Equal(t, "se-01", warehouse.ID)
+
+	// rs-01 é o mais próximo de Porto Alegre, mas está inativo
+	warehouse, err = ws.ResolveOrigin(context.Background(), "", 90020000)
+	assert.NoError(t, err)
+	assert.Equal(t, "se-01", warehouse.ID)
+}
+
+func TestResolveOrigin_InactiveOrUnknown(t *testing.T) {
+	mockWarehouse := new(MockWarehousePort)
+	mockWarehouse.On("ListWarehouses").Return(Warehouses(), nil)
+	ws := NewWarehouseService(mockWarehouse)
+
+	for _, id := range []string{"rs-01", "xx-99"} {
+		_, err := ws.ResolveOrigin(context.Background(), id, 1311000)
+
+		var validationErr *ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "origin.warehouse_id", validationErr.Field)
+	}
+}
+
+func TestResolveOrigin_NoActiveWarehouse(t *testing.T) {
+	mockWarehouse := new(MockWarehousePort)
+	mockWarehouse.On("ListWarehouses").Return([]Warehouse{}, nil)
+	ws := NewWarehouseService(mockWarehouse)
+
+	_, err := ws.ResolveOrigin(context.Background(), "", 1311000)
+
+	assert.ErrorIs(t, err, ErrNoActiveWarehouse)
+}
+
+func TestResolveOrigin_RepositoryError(t *testing.T) {
+	mockWarehouse := new(MockWarehousePort)
+	mockWarehouse.On("ListWarehouses").Return([]Warehouse{}, errors.New("falha no banco"))
+	ws := NewWarehouseService(mockWarehouse)
+
+	_, err := ws.ResolveOrigin(context.Background(), "", 1311000)
+
+	var persistenceErr *PersistenceError
+	assert.ErrorAs(t, err, &persistenceErr)
+}
@@ -1,32 +1,53 @@
 package infra
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/jarcoal/httpmock"
 	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/cache"
 	http2 "github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/http"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/logging"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/telemetry"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"net/http"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 type MockRepo struct {
 	mock.Mock
 }
 
-func (m *MockRepo) GetMetricsQuotes(lastQuotes int) (*quote.Metrics, error) {
-	args := m.Called(lastQuotes)
+func (m *MockRepo) GetMetricsQuotes(ctx context.Context, filter quote.MetricsFilter) (*quote.Metrics, error) {
+	args := m.Called(filter)
 	return args.Get(0).(*quote.Metrics), args.Error(1)
 }
 
-func (m *MockRepo) SaveAllOffers(offers []quote.Offer) error {
-	args := m.Called(offers)
+func (m *MockRepo) SaveQuote(ctx context.Context, quoteData *quote.Quote) error {
+	args := m.Called(quoteData)
 	return args.Error(0)
 }
 
+func (m *MockRepo) GetQuote(ctx context.Context, id string) (*quote.Quote, error) {
+	args := m.Called(id)
+	return args.Get(0).(*quote.Quote), args.Error(1)
+}
+
+func (m *MockRepo) ListQuotes(ctx context.Context, filter quote.QuoteFilter) (*quote.QuotePage, error) {
+	args := m.Called(filter)
+	return args.Get(0).(*quote.QuotePage), args.Error(1)
+}
+
 func ResponseMockFreteRapidoApi(request *http.Request) (*http.Response, error) {
 	var payload http2.FreteRapidoApiRequest
 	if err := json.NewDecoder(request.Body).Decode(&payload); err != nil {
@@ -71,7 +92,7 @@ func ValidRequest() quote.QuoteRequest {
 	validReq := quote.QuoteRequest{
 		Shipper: quote.Shipper{
 			RegisteredNumber: "25438296000158",
-			Token:            "1d52a9b6b78cf07b08586152459a5c90",
+			Token:            "token-de-teste-com-32-caracteres",
 			PlatformCode:     "5AKVkHqCn",
 		},
 		Recipient: quote.Recipient{
@@ -127,17 +148,58 @@ func TestFreteRapidoAdaterSimulateSuccess(t *testing.T) {
 		ResponseMockFreteRapidoApi,
 	)
 	request := ValidRequest()
-	mockRepo := new(MockRepo)
-	mockRepo.On("SaveAllOffers", mock.Anything).Return(nil)
 
-	adapter := NewFreteRapidoAdapter(mockRepo)
+	adapter := NewFreteRapidoAdapter(FreteRapidoConfig{})
 
-	offers, err := adapter.Execute(request)
+	offers, err := adapter.Execute(context.Background(), request)
 
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(offers))
 }
 
+func TestFreteRapidoAdaterPropagatesTraceparent(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	shutdown, err := telemetry.SetupTracing(context.Background(), telemetry.TracesExporterNone)
+	assert.Nil(t, err)
+	defer shutdown(context.Background())
+	received := http.Header{}
+	httpmock.RegisterResponder("POST",
+		"https://sp.freterapido.com/api/v3/quote/simulate",
+		func(req *http.Request) (*http.Response, error) {
+			received.Set("traceparent", req.Header.Get("traceparent"))
+			return ResponseMockFreteRapidoApi(req)
+		},
+	)
+	ctx, span := telemetry.StartSpan(context.Background(), "SimulateQuote")
+	defer span.End()
+
+	_, err = NewFreteRapidoAdapter(FreteRapidoConfig{}).Execute(ctx, ValidRequest())
+
+	assert.Nil(t, err)
+	upstream := trace.SpanContextFromContext(propagation.TraceContext{}.Extract(context.Background(), propagation.HeaderCarrier(received)))
+	assert.True(t, upstream.IsValid())
+	assert.Equal(t, span.SpanContext().TraceID(), upstream.TraceID())
+	assert.NotEqual(t, span.SpanContext().SpanID(), upstream.SpanID())
+}
+
+func TestFreteRapidoAdaterRedactsTokenFromUpstreamErrors(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	request := ValidRequest()
+	httpmock.RegisterResponder("POST",
+		"https://sp.freterapido.com/api/v3/quote/simulate",
+		httpmock.NewStringResponder(401, `{"error":"token `+request.Shipper.Token+` inválido"}`),
+	)
+
+	_, err := NewFreteRapidoAdapter(FreteRapidoConfig{}).Execute(context.Background(), request)
+
+	var rejectedErr *quote.UpstreamRejectedError
+	assert.ErrorAs(t, err, &rejectedErr)
+	assert.NotContains(t, err.Error(), request.Shipper.Token)
+	assert.Contains(t, err.Error(), logging.Redacted)
+}
+
 func TestFreteRapidoAdaterSimulateFailureResponseApi(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
@@ -146,30 +208,243 @@ func TestFreteRapidoAdaterSimulateFailureResponseApi(t *testing.T) {
 		ResponseMockFreteRapidoApi,
 	)
 	request := InvalidRequest()
-	mockRepo := new(MockRepo)
-	adapter := NewFreteRapidoAdapter(mockRepo)
-	_, err := adapter.Execute(request)
+	adapter := NewFreteRapidoAdapter(FreteRapidoConfig{})
+	_, err := adapter.Execute(context.Background(), request)
 	assert.NotNil(t, err)
 	assert.True(t, true, strings.Contains(err.Error(), "frete Rapido Contract returned"))
 
 }
 
-func TestFreteRapidoAdaterSimulateFailureSaveDb(t *testing.T) {
+func TestQuoteHistoryAdapterSaveFailure(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRepo.On("SaveQuote", mock.Anything).Return(fmt.Errorf("Error ao salvar no banco"))
+
+	adapter := NewQuoteHistoryAdapter(mockRepo)
+
+	err := adapter.SaveQuote(context.Background(), &quote.Quote{ID: "abc", Request: ValidRequest()})
+	assert.NotNil(t, err)
+	assert.Equal(t, "Error ao salvar no banco", err.Error())
+}
+
+func TestQuoteHistoryAdapterSavesRequestID(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRepo.On("SaveQuote", mock.MatchedBy(func(q *quote.Quote) bool {
+		return q.RequestID == "checkout-42"
+	})).Return(nil).Once()
+	mockRepo.On("SaveQuote", mock.MatchedBy(func(q *quote.Quote) bool {
+		return q.RequestID == ""
+	})).Return(nil).Once()
+
+	adapter := NewQuoteHistoryAdapter(mockRepo)
+
+	ctx := logging.WithRequestID(context.Background(), "checkout-42")
+	assert.Nil(t, adapter.SaveQuote(ctx, &quote.Quote{ID: "abc", Request: ValidRequest()}))
+	assert.Nil(t, adapter.SaveQuote(context.Background(), &quote.Quote{ID: "def", Request: ValidRequest()}))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFreteRapidoAdaterRetriesServerErrors(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	calls := 0
+	httpmock.RegisterResponder("POST",
+		"https://sp.freterapido.com/api/v3/quote/simulate",
+		func(request *http.Request) (*http.Response, error) {
+			calls++
+			if calls < 3 {
+				return httpmock.NewStringResponse(503, "unavailable"), nil
+			}
+			return ResponseMockFreteRapidoApi(request)
+		},
+	)
+	adapter := NewFreteRapidoAdapter(FreteRapidoConfig{
+		Retry: RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+	})
+
+	offers, err := adapter.Execute(context.Background(), ValidRequest())
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(offers))
+	assert.Equal(t, 3, calls)
+}
+
+func TestFreteRapidoAdaterDoesNotRetryClientErrors(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 	httpmock.RegisterResponder("POST",
 		"https://sp.freterapido.com/api/v3/quote/simulate",
 		ResponseMockFreteRapidoApi,
 	)
-	request := ValidRequest()
-	mockRepo := new(MockRepo)
-	mockRepo.On("SaveAllOffers", mock.Anything).Return(fmt.Errorf("Error ao salvar no banco"))
+	adapter := NewFreteRapidoAdapter(FreteRapidoConfig{
+		Retry: RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+	})
 
-	adapter := NewFreteRapidoAdapter(mockRepo)
+	_, err := adapter.Execute(context.Background(), InvalidRequest())
 
-	_, err := adapter.Execute(request)
-	assert.NotNil(t, err)
-	assert.Equal(t, "Error ao salvar no banco", err.Error())
+	var rejectedErr *quote.UpstreamRejectedError
+	assert.ErrorAs(t, err, &rejectedErr)
+	var statusErr *UpstreamStatusError
+	assert.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, 400, statusErr.StatusCode)
+	assert.Equal(t, 1, httpmock.GetTotalCallCount())
+	assert.Equal(t, CircuitClosed, adapter.BreakerStats().State)
+}
+
+func TestFreteRapidoAdaterRetriesRateLimiting(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	calls := 0
+	httpmock.RegisterResponder("POST",
+		"https://sp.freterapido.com/api/v3/quote/simulate",
+		func(request *http.Request) (*http.Response, error) {
+			calls++
+			if calls == 1 {
+				return httpmock.NewStringResponse(429, "too many requests"), nil
+			}
+			return ResponseMockFreteRapidoApi(request)
+		},
+	)
+	adapter := NewFreteRapidoAdapter(FreteRapidoConfig{
+		Retry: RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+	})
+
+	offers, err := adapter.Execute(context.Background(), ValidRequest())
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(offers))
+	assert.Equal(t, 2, calls)
+}
+
+func TestFreteRapidoAdaterRateLimitPastDeadlineCountsAsFailure(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST",
+		"https://sp.freterapido.com/api/v3/quote/simulate",
+		func(request *http.Request) (*http.Response, error) {
+			response := httpmock.NewStringResponse(429, "too many requests")
+			response.Header.Set("Retry-After", "30")
+			return response, nil
+		},
+	)
+	adapter := NewFreteRapidoAdapter(FreteRapidoConfig{
+		Retry: RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := adapter.Execute(ctx, ValidRequest())
+
+	var unavailableErr *quote.UpstreamUnavailableError
+	assert.ErrorAs(t, err, &unavailableErr)
+	var statusErr *UpstreamStatusError
+	assert.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, 30*time.Second, statusErr.RetryAfter)
+	assert.Equal(t, 1, httpmock.GetTotalCallCount())
+	assert.Equal(t, 1, adapter.BreakerStats().ConsecutiveFailures)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 4, 12, 10, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 5*time.Second, parseRetryAfter("5", now))
+	assert.Equal(t, 90*time.Second, parseRetryAfter("Sat, 12 Apr 2025 10:01:30 GMT", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("Sat, 12 Apr 2025 09:00:00 GMT", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("logo", now))
+}
+
+func TestFreteRapidoAdaterCircuitBreakerFailsFast(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST",
+		"https://sp.freterapido.com/api/v3/quote/simulate",
+		httpmock.NewStringResponder(500, "internal error"),
+	)
+	adapter := NewFreteRapidoAdapter(FreteRapidoConfig{
+		Retry:            RetryPolicy{MaxRetries: 0},
+		BreakerThreshold: 2,
+		BreakerTimeout:   time.Minute,
+	})
+
+	for i := 0; i < 2; i++ {
+		_, err := adapter.Execute(context.Background(), ValidRequest())
+		assert.NotNil(t, err)
+	}
+	_, err := adapter.Execute(context.Background(), ValidRequest())
+
+	var unavailableErr *quote.UpstreamUnavailableError
+	assert.ErrorAs(t, err, &unavailableErr)
+	var openErr *CircuitOpenError
+	assert.ErrorAs(t, err, &openErr)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 2, httpmock.GetTotalCallCount())
+	assert.Equal(t, CircuitOpen, adapter.BreakerStats().State)
+	assert.Equal(t, float64(CircuitOpen), testutil.ToFloat64(telemetry.FreteRapidoCircuitState))
+}
+
+func TestFreteRapidoAdaterHealthCheckFollowsLastCalls(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST",
+		"https://sp.freterapido.com/api/v3/quote/simulate",
+		httpmock.NewStringResponder(500, "internal error"),
+	)
+	adapter := NewFreteRapidoAdapter(FreteRapidoConfig{
+		Retry:            RetryPolicy{MaxRetries: 0},
+		BreakerThreshold: 2,
+		BreakerTimeout:   time.Minute,
+	})
+	assert.Nil(t, adapter.HealthCheck(context.Background()))
+
+	adapter.Execute(context.Background(), ValidRequest())
+	assert.ErrorContains(t, adapter.HealthCheck(context.Background()), "última chamada falhou")
+
+	adapter.Execute(context.Background(), ValidRequest())
+	assert.ErrorContains(t, adapter.HealthCheck(context.Background()), "aberto")
+	assert.Equal(t, 2, httpmock.GetTotalCallCount())
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	now := time.Now()
+	breaker := NewCircuitBreaker("teste", 1, time.Second)
+	breaker.now = func() time.Time { return now }
+
+	breaker.Failure()
+	assert.Equal(t, CircuitOpen, breaker.State())
+	assert.NotNil(t, breaker.Allow())
+
+	now = now.Add(time.Second)
+	assert.Nil(t, breaker.Allow())
+	assert.Equal(t, CircuitHalfOpen, breaker.State())
+	breaker.Failure()
+	assert.Equal(t, CircuitOpen, breaker.State())
+
+	now = now.Add(time.Second)
+	assert.Nil(t, breaker.Allow())
+	breaker.Success()
+	assert.Equal(t, CircuitClosed, breaker.State())
+	assert.Equal(t, 2, breaker.Stats().TotalOpens)
+}
+
+func TestCircuitBreakerHalfOpenAllowsSingleProbe(t *testing.T) {
+	now := time.Now()
+	breaker := NewCircuitBreaker("teste", 1, time.Second)
+	breaker.now = func() time.Time { return now }
+	breaker.Failure()
+	now = now.Add(time.Second)
+
+	assert.Nil(t, breaker.Allow())
+	assert.ErrorIs(t, breaker.Allow(), ErrCircuitOpen)
+	assert.ErrorIs(t, breaker.Allow(), ErrCircuitOpen)
+	assert.Equal(t, 2, breaker.Stats().TotalRejected)
+
+	breaker.Cancel()
+	assert.Equal(t, CircuitHalfOpen, breaker.State())
+	assert.Nil(t, breaker.Allow())
+	breaker.Success()
+	assert.Equal(t, CircuitClosed, breaker.State())
+	assert.Nil(t, breaker.Allow())
+	assert.Nil(t, breaker.Allow())
 }
 
 func TestGetMetricsQuotes(t *testing.T) {
@@ -177,6 +452,551 @@ func TestGetMetricsQuotes(t *testing.T) {
 	mockRepo.On("GetMetricsQuotes", mock.Anything).Return(&quote.Metrics{}, nil)
 
 	adapter := NewMetricsAdapter(mockRepo)
-	_, err := adapter.Execute(0)
+	_, err := adapter.Execute(context.Background(), quote.MetricsFilter{})
+	assert.Nil(t, err)
+}
+
+type MockProvider struct {
+	mock.Mock
+}
+
+func (m *MockProvider) Execute(ctx context.Context, quoteData quote.QuoteRequest) ([]quote.Offer, error) {
+	args := m.Called(quoteData)
+	return args.Get(0).([]quote.Offer), args.Error(1)
+}
+
+func TestMultiCarrierAdapterMergesOffers(t *testing.T) {
+	request := ValidRequest()
+	freteRapido := new(MockProvider)
+	freteRapido.On("Execute", request).Return([]quote.Offer{
+		{Carrier: "CORREIOS", Service: "SEDEX", FinalPrice: 30, DeliveryTime: 1},
+	}, nil)
+	jadlog := new(MockProvider)
+	jadlog.On("Execute", request).Return([]quote.Offer{
+		{Carrier: "JADLOG", Service: ".PACKAGE", FinalPrice: 25, DeliveryTime: 3},
+	}, nil)
+
+	adapter := NewMultiCarrierAdapter(nil,
+		CarrierProvider{Name: "frete_rapido", Provider: freteRapido},
+		CarrierProvider{Name: "jadlog", Provider: jadlog},
+	)
+	offers, err := adapter.Execute(context.Background(), request)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(offers))
+	assert.Equal(t, "frete_rapido", offers[0].Source)
+	assert.Equal(t, "jadlog", offers[1].Source)
+}
+
+func TestMultiCarrierAdapterPartialFailure(t *testing.T) {
+	request := ValidRequest()
+	freteRapido := new(MockProvider)
+	freteRapido.On("Execute", request).Return([]quote.Offer(nil), fmt.Errorf("timeout"))
+	jadlog := new(MockProvider)
+	jadlog.On("Execute", request).Return([]quote.Offer{
+		{Carrier: "JADLOG", Service: ".PACKAGE", FinalPrice: 25, DeliveryTime: 3},
+	}, nil)
+
+	adapter := NewMultiCarrierAdapter(nil,
+		CarrierProvider{Name: "frete_rapido", Provider: freteRapido},
+		CarrierProvider{Name: "jadlog", Provider: jadlog},
+	)
+	offers, err := adapter.Execute(context.Background(), request)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(offers))
+	assert.Equal(t, "jadlog", offers[0].Source)
+}
+
+func TestMultiCarrierAdapterAllProvidersFail(t *testing.T) {
+	request := ValidRequest()
+	freteRapido := new(MockProvider)
+	freteRapido.On("Execute", request).Return([]quote.Offer(nil), fmt.Errorf("timeout"))
+
+	adapter := NewMultiCarrierAdapter(nil, CarrierProvider{Name: "frete_rapido", Provider: freteRapido})
+	_, err := adapter.Execute(context.Background(), request)
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "provedor frete_rapido: timeout")
+}
+
+func TestMultiCarrierAdapterContextCanceled(t *testing.T) {
+	request := ValidRequest()
+	freteRapido := new(MockProvider)
+	freteRapido.On("Execute", request).Return([]quote.Offer(nil), context.Canceled)
+
+	adapter := NewMultiCarrierAdapter(nil, CarrierProvider{Name: "frete_rapido", Provider: freteRapido})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := adapter.Execute(ctx, request)
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestParseWarehouses(t *testing.T) {
+	warehouses, err := ParseWarehouses(`[
+		{"id": "sp-01", "name": "CD São Paulo", "registered_number": "25.438.296/0001-58", "zipcode": 1311000},
+		{"id": "se-01", "name": "CD Aracaju", "registered_number": "25438296000158", "zipcode": 49157021, "active": false}
+	]`)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(warehouses))
+	assert.Equal(t, "25438296000158", warehouses[0].RegisteredNumber)
+	assert.True(t, warehouses[0].Active)
+	assert.False(t, warehouses[1].Active)
+
+	_, err = ParseWarehouses(`[{"name": "sem id"}]`)
+	assert.NotNil(t, err)
+
+	_, err = ParseWarehouses(`[
+		{"id": "sp-01", "registered_number": "25438296000158", "zipcode": 1311000},
+		{"id": "se-01", "registered_number": "11111111111111", "zipcode": 0}
+	]`)
+	var violations quote.ValidationErrors
+	assert.ErrorAs(t, err, &violations)
+	assert.Equal(t, 2, len(violations))
+	assert.Equal(t, "[1].registered_number", violations[0].Field)
+	assert.Equal(t, "[1].zipcode", violations[1].Field)
+}
+
+type failingCache struct{}
+
+func (failingCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	return errors.New("redis fora do ar")
+}
+
+func (failingCache) Get(ctx context.Context, key string) (string, error) {
+	return "", errors.New("redis fora do ar")
+}
+
+func (failingCache) Delete(ctx context.Context, keys ...string) error {
+	return errors.New("redis fora do ar")
+}
+
+func (failingCache) ScanPrefix(ctx context.Context, prefix string) ([]string, error) {
+	return nil, errors.New("redis fora do ar")
+}
+
+func (failingCache) Ping(ctx context.Context) error {
+	return errors.New("redis fora do ar")
+}
+
+func TestCachingQuoteAdapterServesFromCache(t *testing.T) {
+	request := ValidRequest()
+	provider := new(MockProvider)
+	provider.On("Execute", request).Return([]quote.Offer{
+		{Carrier: "CORREIOS", Service: "SEDEX", FinalPrice: 30, DeliveryTime: 1, Source: "frete_rapido"},
+	}, nil).Once()
+
+	adapter := NewCachingQuoteAdapter(provider, cache.NewMemoryCache(), CachingConfig{TTL: time.Minute})
+	first, err := adapter.Execute(context.Background(), request)
+	assert.Nil(t, err)
+	second, err := adapter.Execute(context.Background(), request)
+	assert.Nil(t, err)
+
+	assert.Equal(t, first, second)
+	provider.AssertNumberOfCalls(t, "Execute", 1)
+}
+
+func TestCachingQuoteAdapterReportsCacheHits(t *testing.T) {
+	request := ValidRequest()
+	provider := new(MockProvider)
+	provider.On("Execute", request).Return([]quote.Offer{
+		{Carrier: "CORREIOS", Service: "SEDEX", FinalPrice: 30, DeliveryTime: 1, Source: "frete_rapido"},
+	}, nil).After(20 * time.Millisecond).Once()
+
+	adapter := NewCachingQuoteAdapter(provider, cache.NewMemoryCache(), CachingConfig{TTL: time.Minute})
+	miss, err := adapter.Simulate(context.Background(), request)
+	assert.Nil(t, err)
+	hit, err := adapter.Simulate(context.Background(), request)
+	assert.Nil(t, err)
+
+	assert.False(t, miss.Cached)
+	assert.True(t, hit.Cached)
+	assert.Equal(t, miss.Offers, hit.Offers)
+	assert.GreaterOrEqual(t, hit.UpstreamLatency, 20*time.Millisecond)
+}
+
+func TestCachingQuoteAdapterDoesNotCacheEmptyOrFailedResults(t *testing.T) {
+	request := ValidRequest()
+	provider := new(MockProvider)
+	provider.On("Execute", request).Return([]quote.Offer(nil), nil).Twice()
+
+	adapter := NewCachingQuoteAdapter(provider, cache.NewMemoryCache(), CachingConfig{TTL: time.Minute})
+	adapter.Execute(context.Background(), request)
+	adapter.Execute(context.Background(), request)
+
+	provider.AssertNumberOfCalls(t, "Execute", 2)
+}
+
+func TestCachingQuoteAdapterIgnoresCacheErrors(t *testing.T) {
+	request := ValidRequest()
+	provider := new(MockProvider)
+	provider.On("Execute", request).Return([]quote.Offer{
+		{Carrier: "CORREIOS", Service: "SEDEX", FinalPrice: 30, DeliveryTime: 1},
+	}, nil)
+
+	adapter := NewCachingQuoteAdapter(provider, failingCache{}, CachingConfig{TTL: time.Minute})
+	offers, err := adapter.Execute(context.Background(), request)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(offers))
+}
+
+type blockingProvider struct {
+	calls   atomic.Int32
+	release chan struct{}
+	offers  []quote.Offer
+}
+
+func (p *blockingProvider) Execute(ctx context.Context, quoteData quote.QuoteRequest) ([]quote.Offer, error) {
+	p.calls.Add(1)
+	<-p.release
+	return p.offers, nil
+}
+
+func TestCachingQuoteAdapterCoalescesConcurrentRequests(t *testing.T) {
+	request := ValidRequest()
+	provider := &blockingProvider{
+		release: make(chan struct{}),
+		offers:  []quote.Offer{{Carrier: "CORREIOS", Service: "SEDEX", FinalPrice: 30, DeliveryTime: 1}},
+	}
+	adapter := NewCachingQuoteAdapter(provider, cache.NewMemoryCache(), CachingConfig{TTL: time.Minute})
+
+	var wg sync.WaitGroup
+	results := make([][]quote.Offer, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = adapter.Execute(context.Background(), request)
+		}(i)
+	}
+	assert.Eventually(t, func() bool { return provider.calls.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(provider.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), provider.calls.Load())
+	for _, offers := range results {
+		assert.Equal(t, provider.offers, offers)
+	}
+}
+
+func TestCachingQuoteAdapterCountsOnlyTheLeaderAsUpstream(t *testing.T) {
+	request := ValidRequest()
+	provider := &blockingProvider{
+		release: make(chan struct{}),
+		offers:  []quote.Offer{{Carrier: "CORREIOS", Service: "SEDEX", FinalPrice: 30, DeliveryTime: 1}},
+	}
+	adapter := NewCachingQuoteAdapter(provider, cache.NewMemoryCache(), CachingConfig{TTL: time.Minute})
+
+	var wg sync.WaitGroup
+	results := make([]*quote.Simulation, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = adapter.Simulate(context.Background(), request)
+		}(i)
+	}
+	key := cache.QuoteCacheKey(request)
+	assert.Eventually(t, func() bool {
+		adapter.flights.mu.Lock()
+		defer adapter.flights.mu.Unlock()
+		call, ok := adapter.flights.calls[key]
+		return ok && call.waiters == len(results)
+	}, time.Second, time.Millisecond)
+	close(provider.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), provider.calls.Load())
+	upstream := 0
+	for _, simulation := range results {
+		if !simulation.Cached {
+			upstream++
+		}
+		assert.Equal(t, results[0].UpstreamLatency, simulation.UpstreamLatency)
+	}
+	assert.Equal(t, 1, upstream)
+}
+
+func TestCachingQuoteAdapterWaitsForAbandonedFetches(t *testing.T) {
+	provider := &blockingProvider{
+		release: make(chan struct{}),
+		offers:  []quote.Offer{{Carrier: "CORREIOS", Service: "SEDEX", FinalPrice: 30, DeliveryTime: 1}},
+	}
+	memoryCache := cache.NewMemoryCache()
+	adapter := NewCachingQuoteAdapter(provider, memoryCache, CachingConfig{TTL: time.Minute})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := adapter.Execute(ctx, ValidRequest())
+	assert.ErrorIs(t, err, context.Canceled)
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer waitCancel()
+	assert.ErrorIs(t, adapter.Wait(waitCtx), context.DeadlineExceeded)
+
+	close(provider.release)
+	assert.Nil(t, adapter.Wait(context.Background()))
+	_, err = memoryCache.Get(context.Background(), cache.QuoteCacheKey(ValidRequest()))
+	assert.Nil(t, err)
+}
+
+func TestCachingQuoteAdapterServesStaleWhileRevalidating(t *testing.T) {
+	request := ValidRequest()
+	now := time.Date(2025, 4, 12, 9, 0, 0, 0, time.UTC)
+	provider := new(MockProvider)
+	provider.On("Execute", request).Return([]quote.Offer{
+		{Carrier: "CORREIOS", Service: "SEDEX", FinalPrice: 30, DeliveryTime: 1},
+	}, nil).Once()
+	provider.On("Execute", request).Return([]quote.Offer{
+		{Carrier: "CORREIOS", Service: "SEDEX", FinalPrice: 35, DeliveryTime: 1},
+	}, nil).Once()
+
+	adapter := NewCachingQuoteAdapter(provider, cache.NewMemoryCache(), CachingConfig{
+		TTL:                  time.Minute,
+		StaleWhileRevalidate: true,
+		MaxStaleness:         time.Minute,
+	})
+	adapter.now = func() time.Time { return now }
+	_, err := adapter.Execute(context.Background(), request)
+	assert.Nil(t, err)
+
+	now = now.Add(90 * time.Second)
+	stale, err := adapter.Execute(context.Background(), request)
+	assert.Nil(t, err)
+	assert.Equal(t, 30.0, stale[0].FinalPrice)
+
+	assert.Eventually(t, func() bool {
+		offers, _ := adapter.Execute(context.Background(), request)
+		return offers[0].FinalPrice == 35
+	}, time.Second, 5*time.Millisecond)
+	provider.AssertNumberOfCalls(t, "Execute", 2)
+}
+
+func TestCachingQuoteAdapterIgnoresEntriesPastMaxStaleness(t *testing.T) {
+	request := ValidRequest()
+	now := time.Date(2025, 4, 12, 9, 0, 0, 0, time.UTC)
+	provider := new(MockProvider)
+	provider.On("Execute", request).Return([]quote.Offer{
+		{Carrier: "CORREIOS", Service: "SEDEX", FinalPrice: 30, DeliveryTime: 1},
+	}, nil).Once()
+	provider.On("Execute", request).Return([]quote.Offer{
+		{Carrier: "CORREIOS", Service: "SEDEX", FinalPrice: 35, DeliveryTime: 1},
+	}, nil).Once()
+
+	adapter := NewCachingQuoteAdapter(provider, cache.NewMemoryCache(), CachingConfig{
+		TTL:                  time.Minute,
+		StaleWhileRevalidate: true,
+		MaxStaleness:         time.Minute,
+	})
+	adapter.now = func() time.Time { return now }
+	adapter.Execute(context.Background(), request)
+
+	now = now.Add(3 * time.Minute)
+	offers, err := adapter.Execute(context.Background(), request)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 35.0, offers[0].FinalPrice)
+}
+
+func TestCachingQuoteAdapterExpiresPartialResultsSooner(t *testing.T) {
+	request := ValidRequest()
+	now := time.Date(2025, 4, 12, 9, 0, 0, 0, time.UTC)
+	freteRapido := new(MockProvider)
+	freteRapido.On("Execute", request).Return([]quote.Offer(nil), fmt.Errorf("timeout")).Once()
+	freteRapido.On("Execute", request).Return([]quote.Offer{
+		{Carrier: "CORREIOS", Service: "SEDEX", FinalPrice: 30, DeliveryTime: 1},
+	}, nil).Once()
+	jadlog := new(MockProvider)
+	jadlog.On("Execute", request).Return([]quote.Offer{
+		{Carrier: "JADLOG", Service: ".PACKAGE", FinalPrice: 25, DeliveryTime: 3},
+	}, nil)
+
+	adapter := NewCachingQuoteAdapter(NewMultiCarrierAdapter(nil,
+		CarrierProvider{Name: "frete_rapido", Provider: freteRapido},
+		CarrierProvider{Name: "jadlog", Provider: jadlog},
+	), cache.NewMemoryCache(), CachingConfig{
+		TTL:                  30 * time.Minute,
+		StaleWhileRevalidate: true,
+		PartialTTL:           time.Minute,
+	})
+	adapter.now = func() time.Time { return now }
+	partial, err := adapter.Execute(context.Background(), request)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(partial))
+
+	now = now.Add(90 * time.Second)
+	complete, err := adapter.Execute(context.Background(), request)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(complete))
+	freteRapido.AssertNumberOfCalls(t, "Execute", 2)
+}
+
+func TestFlightGroupCancelsWhenLastWaiterLeaves(t *testing.T) {
+	group := newFlightGroup()
+	started := make(chan struct{})
+	canceled := make(chan struct{})
+	fn := func(ctx context.Context) ([]quote.Offer, error) {
+		close(started)
+		<-ctx.Done()
+		close(canceled)
+		return nil, ctx.Err()
+	}
+
+	first, cancelFirst := context.WithCancel(context.Background())
+	second, cancelSecond := context.WithCancel(context.Background())
+	errs := make(chan error, 2)
+	go func() { _, err := group.Do(first, "key", fn); errs <- err }()
+	<-started
+	go func() { _, err := group.Do(second, "key", fn); errs <- err }()
+	assert.Eventually(t, func() bool {
+		group.mu.Lock()
+		defer group.mu.Unlock()
+		return group.calls["key"].waiters == 2
+	}, time.Second, time.Millisecond)
+
+	cancelFirst()
+	assert.ErrorIs(t, <-errs, context.Canceled)
+	select {
+	case <-canceled:
+		t.Fatal("chamada cancelada com um chamador ainda esperando")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	cancelSecond()
+	assert.ErrorIs(t, <-errs, context.Canceled)
+	<-canceled
+	assert.Nil(t, group.Wait(context.Background()))
+}
+
+func TestFlightGroupKeepsCallerDeadline(t *testing.T) {
+	group := newFlightGroup()
+	deadline := time.Now().Add(time.Minute)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	var shared time.Time
+	_, err := group.Do(ctx, "key", func(ctx context.Context) ([]quote.Offer, error) {
+		shared, _ = ctx.Deadline()
+		return nil, nil
+	})
+
+	assert.Nil(t, err)
+	assert.True(t, shared.Equal(deadline))
+}
+
+func TestFlightGroupBoundsBackgroundRefresh(t *testing.T) {
+	group := newFlightGroup()
+	done := make(chan error, 1)
+	group.Go(context.Background(), "key", 10*time.Millisecond, func(ctx context.Context) ([]quote.Offer, error) {
+		<-ctx.Done()
+		done <- ctx.Err()
+		return nil, ctx.Err()
+	})
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		t.Fatal("atualização em segundo plano sem limite de tempo")
+	}
+}
+
+func TestCachingQuoteAdapterStats(t *testing.T) {
+	request := ValidRequest()
+	provider := new(MockProvider)
+	provider.On("Execute", request).Return([]quote.Offer{
+		{Carrier: "CORREIOS", Service: "SEDEX", FinalPrice: 30, DeliveryTime: 1},
+	}, nil).Once()
+
+	adapter := NewCachingQuoteAdapter(provider, cache.NewMemoryCache(), CachingConfig{TTL: time.Minute})
+	adapter.Execute(context.Background(), request)
+	adapter.Execute(context.Background(), request)
+	adapter.Execute(context.Background(), request)
+
+	stats := adapter.Stats()
+	assert.Equal(t, int64(2), stats.Hits)
+	assert.Equal(t, int64(1), stats.Misses)
+	assert.Equal(t, int64(0), stats.Errors)
+	assert.InDelta(t, 2.0/3.0, stats.HitRate(), 0.001)
+
+	failing := NewCachingQuoteAdapter(provider, failingCache{}, CachingConfig{TTL: time.Minute})
+	provider.On("Execute", request).Return([]quote.Offer{
+		{Carrier: "CORREIOS", Service: "SEDEX", FinalPrice: 30, DeliveryTime: 1},
+	}, nil)
+	failing.Execute(context.Background(), request)
+	assert.Equal(t, int64(2), failing.Stats().Errors)
+}
+
+func TestCachingQuoteAdapterCountsOperationsForAnyBackend(t *testing.T) {
+	request := ValidRequest()
+	provider := new(MockProvider)
+	provider.On("Execute", request).Return([]quote.Offer{
+		{Carrier: "CORREIOS", Service: "SEDEX", FinalPrice: 30, DeliveryTime: 1},
+	}, nil).Once()
+	hits := testutil.ToFloat64(telemetry.CacheOperations.WithLabelValues("get", "hit"))
+	misses := testutil.ToFloat64(telemetry.CacheOperations.WithLabelValues("get", "miss"))
+
+	adapter := NewCachingQuoteAdapter(provider, cache.NewMemoryCache(), CachingConfig{TTL: time.Minute})
+	adapter.Execute(context.Background(), request)
+	adapter.Execute(context.Background(), request)
+
+	assert.Equal(t, hits+1, testutil.ToFloat64(telemetry.CacheOperations.WithLabelValues("get", "hit")))
+	assert.Equal(t, misses+1, testutil.ToFloat64(telemetry.CacheOperations.WithLabelValues("get", "miss")))
+}
+
+func TestCachingQuoteAdapterPurges(t *testing.T) {
+	withSKU := func(zipcode int, skus ...string) quote.QuoteRequest {
+		request := ValidRequest()
+		request.Recipient.Zipcode = zipcode
+		volumes := append([]quote.Volume(nil), request.Dispatchers[0].Volumes...)
+		for i := range volumes {
+			volumes[i].SKU = skus[i]
+		}
+		request.Dispatchers = []quote.Dispatcher{request.Dispatchers[0]}
+		request.Dispatchers[0].Volumes = volumes
+		return request
+	}
+	sp := withSKU(1311000, "abc-527", "abc-623")
+	rj := withSKU(20040002, "abc-527", "xyz-001")
+	ba := withSKU(40010000, "xyz-001", "xyz-002")
+
+	provider := new(MockProvider)
+	provider.On("Execute", mock.Anything).Return([]quote.Offer{
+		{Carrier: "CORREIOS", Service: "SEDEX", FinalPrice: 30, DeliveryTime: 1},
+	}, nil)
+	memoryCache := cache.NewMemoryCache()
+	adapter := NewCachingQuoteAdapter(provider, memoryCache, CachingConfig{TTL: time.Minute})
+	for _, request := range []quote.QuoteRequest{sp, rj, ba} {
+		adapter.Execute(context.Background(), request)
+	}
+
+	value, err := adapter.Inspect(context.Background(), cache.QuoteCacheKey(sp))
+	assert.Nil(t, err)
+	assert.Contains(t, value, "CORREIOS")
+	_, err = adapter.Inspect(context.Background(), "outra:chave")
+	assert.ErrorIs(t, err, cache.ErrCacheMiss)
+
+	deleted, err := adapter.PurgeSKU(context.Background(), "abc-527")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, deleted)
+	_, err = memoryCache.Get(context.Background(), cache.QuoteCacheKey(sp))
+	assert.ErrorIs(t, err, cache.ErrCacheMiss)
+	_, err = memoryCache.Get(context.Background(), cache.QuoteCacheKey(rj))
+	assert.ErrorIs(t, err, cache.ErrCacheMiss)
+
+	deleted, err = adapter.PurgeZipcode(context.Background(), 40010000)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, deleted)
+
+	adapter.Execute(context.Background(), sp)
+	deleted, err = adapter.Flush(context.Background())
 	assert.Nil(t, err)
+	assert.Equal(t, 1, deleted)
+	keys, _ := memoryCache.ScanPrefix(context.Background(), cache.QuoteKeyPrefix)
+	assert.Empty(t, keys)
 }
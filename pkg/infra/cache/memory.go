@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	value     string
+	expiresAt time.Time
+}
+
+// MemoryCache implementa IRedisCache em memória, para testes e para
+// instalações de um único nó sem Redis. Entradas expiradas são removidas
+// na leitura.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	now     func() time.Time
+}
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		entries: make(map[string]memoryEntry),
+		now:     time.Now,
+	}
+}
+
+func (mc *MemoryCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	valueMarshal, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	entry := memoryEntry{value: string(valueMarshal)}
+	if expiration > 0 {
+		entry.expiresAt = mc.now().Add(expiration)
+	}
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.entries[key] = entry
+	return nil
+}
+
+func (mc *MemoryCache) Get(ctx context.Context, key string) (string, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	entry, ok := mc.entries[key]
+	if !ok {
+		return "", ErrCacheMiss
+	}
+	if !entry.expiresAt.IsZero() && !mc.now().Before(entry.expiresAt) {
+		delete(mc.entries, key)
+		return "", ErrCacheMiss
+	}
+	return entry.value, nil
+}
+
+func (mc *MemoryCache) Delete(ctx context.Context, keys ...string) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	for _, key := range keys {
+		delete(mc.entries, key)
+	}
+	return nil
+}
+
+func (mc *MemoryCache) ScanPrefix(ctx context.Context, prefix string) ([]string, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	now := mc.now()
+	var keys []string
+	for key, entry := range mc.entries {
+		if !entry.expiresAt.IsZero() && !now.Before(entry.expiresAt) {
+			continue
+		}
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Ping sempre responde: o cache em memória vive no próprio processo.
+func (mc *MemoryCache) Ping(ctx context.Context) error {
+	return nil
+}
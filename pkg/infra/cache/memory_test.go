@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheExpiresEntries(t *testing.T) {
+	now := time.Date(2025, 4, 12, 9, 0, 0, 0, time.UTC)
+	memoryCache := NewMemoryCache()
+	memoryCache.now = func() time.Time { return now }
+
+	assert.Nil(t, memoryCache.Set(context.Background(), "chave", map[string]int{"a": 1}, time.Minute))
+	value, err := memoryCache.Get(context.Background(), "chave")
+	assert.Nil(t, err)
+	assert.Equal(t, `{"a":1}`, value)
+
+	now = now.Add(time.Minute)
+	_, err = memoryCache.Get(context.Background(), "chave")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+
+	_, err = memoryCache.Get(context.Background(), "inexistente")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+}
+
+func TestMemoryCacheScanPrefixAndDelete(t *testing.T) {
+	memoryCache := NewMemoryCache()
+	for _, key := range []string{"quote:v2:a", "quote:v2:b", "outra:c"} {
+		memoryCache.Set(context.Background(), key, 1, time.Minute)
+	}
+
+	keys, err := memoryCache.ScanPrefix(context.Background(), "quote:v2:")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"quote:v2:a", "quote:v2:b"}, keys)
+
+	assert.Nil(t, memoryCache.Delete(context.Background(), keys...))
+	keys, _ = memoryCache.ScanPrefix(context.Background(), "")
+	assert.Equal(t, []string{"outra:c"}, keys)
+}
@@ -0,0 +1,148 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
+	"sort"
+	"strings"
+)
+
+// QuoteKeyVersion entra no prefixo da chave. Mude sempre que o formato
+// canônico ou o conteúdo cacheado mudar, para que entradas antigas deixem de
+// ser lidas em vez de serem interpretadas com o formato novo.
+const QuoteKeyVersion = "v2"
+
+// QuoteKeyPrefix é comum a todas as chaves de cotação da versão atual,
+// incluindo os índices por SKU.
+const QuoteKeyPrefix = "quote:" + QuoteKeyVersion + ":"
+
+const quoteSKUIndexPrefix = QuoteKeyPrefix + "sku:"
+
+type canonicalVolume struct {
+	Category      string  `json:"category"`
+	Amount        int     `json:"amount"`
+	UnitaryWeight float64 `json:"unitary_weight"`
+	UnitaryPrice  float64 `json:"unitary_price"`
+	Height        float64 `json:"height"`
+	Width         float64 `json:"width"`
+	Length        float64 `json:"length"`
+}
+
+type canonicalDispatcher struct {
+	WarehouseID      string            `json:"warehouse_id"`
+	RegisteredNumber string            `json:"registered_number"`
+	Zipcode          int               `json:"zipcode"`
+	Volumes          []canonicalVolume `json:"volumes"`
+}
+
+type canonicalQuoteRequest struct {
+	ShipperRegisteredNumber   string                `json:"shipper_registered_number"`
+	PlatformCode              string                `json:"platform_code"`
+	RecipientType             int                   `json:"recipient_type"`
+	RecipientCountry          string                `json:"recipient_country"`
+	RecipientZipcode          int                   `json:"recipient_zipcode"`
+	RecipientRegisteredNumber string                `json:"recipient_registered_number"`
+	Dispatchers               []canonicalDispatcher `json:"dispatchers"`
+}
+
+// QuoteCacheKey monta a chave de cache a partir da QuoteRequest completa:
+// remetente, destino, cada expedidor e todos os atributos de cada volume.
+// A requisição é normalizada e a ordem de expedidores e volumes não importa,
+// então pedidos equivalentes caem na mesma chave. O token do remetente fica
+// de fora: ele não muda o preço e não deve ser derivável da chave.
+//
+// Formato: quote:v2:<CEP destino com 8 dígitos>:<sha256 hex>
+func QuoteCacheKey(request quote.QuoteRequest) string {
+	request.Normalize()
+
+	canonical := canonicalQuoteRequest{
+		ShipperRegisteredNumber:   request.Shipper.RegisteredNumber,
+		PlatformCode:              request.Shipper.PlatformCode,
+		RecipientType:             request.Recipient.Type,
+		RecipientCountry:          request.Recipient.Country,
+		RecipientZipcode:          request.Recipient.Zipcode,
+		RecipientRegisteredNumber: request.Recipient.RegisteredNumber,
+	}
+	for _, d := range request.Dispatchers {
+		dispatcher := canonicalDispatcher{
+			WarehouseID:      d.WarehouseID,
+			RegisteredNumber: d.RegisteredNumber,
+			Zipcode:          d.Zipcode,
+		}
+		for _, v := range d.Volumes {
+			dispatcher.Volumes = append(dispatcher.Volumes, canonicalVolume{
+				Category:      v.Category,
+				Amount:        v.Amount,
+				UnitaryWeight: v.UnitaryWeight,
+				UnitaryPrice:  v.UnitaryPrice,
+				Height:        v.Height,
+				Width:         v.Width,
+				Length:        v.Length,
+			})
+		}
+		sort.Slice(dispatcher.Volumes, func(i, j int) bool {
+			return volumeSortKey(dispatcher.Volumes[i]) < volumeSortKey(dispatcher.Volumes[j])
+		})
+		canonical.Dispatchers = append(canonical.Dispatchers, dispatcher)
+	}
+	sort.Slice(canonical.Dispatchers, func(i, j int) bool {
+		a, b := canonical.Dispatchers[i], canonical.Dispatchers[j]
+		if a.WarehouseID != b.WarehouseID {
+			return a.WarehouseID < b.WarehouseID
+		}
+		return a.Zipcode < b.Zipcode
+	})
+
+	// json.Marshal de structs segue a ordem dos campos e formata floats de
+	// forma determinística, o que basta como serialização canônica.
+	raw, err := json.Marshal(canonical)
+	if err != nil {
+		panic(err)
+	}
+	sum := sha256.Sum256(raw)
+	return QuoteZipcodePrefix(request.Recipient.Zipcode) + hex.EncodeToString(sum[:])
+}
+
+// QuoteZipcodePrefix agrupa as chaves de cotação de um CEP de destino.
+func QuoteZipcodePrefix(zipcode int) string {
+	return fmt.Sprintf("%s%08d:", QuoteKeyPrefix, zipcode)
+}
+
+// O SKU não entra no hash porque não muda o preço. Para permitir expurgar por
+// SKU, cada entrada ganha uma chave índice por SKU cujo sufixo é a própria
+// chave da entrada: quote:v2:sku:<sha256 hex do sku>:<chave da entrada>. O SKU
+// vai como hash porque pode conter ":" e, cru, o prefixo de "A" casaria com
+// os índices de "A:B".
+
+func QuoteSKUIndexPrefix(sku string) string {
+	sum := sha256.Sum256([]byte(sku))
+	return quoteSKUIndexPrefix + hex.EncodeToString(sum[:]) + ":"
+}
+
+func IsQuoteSKUIndexKey(key string) bool {
+	return strings.HasPrefix(key, quoteSKUIndexPrefix)
+}
+
+// QuoteSKUIndexKeys devolve uma chave índice para cada SKU distinto da cotação.
+func QuoteSKUIndexKeys(request quote.QuoteRequest, entryKey string) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, d := range request.Dispatchers {
+		for _, v := range d.Volumes {
+			if v.SKU == "" || seen[v.SKU] {
+				continue
+			}
+			seen[v.SKU] = true
+			keys = append(keys, QuoteSKUIndexPrefix(v.SKU)+entryKey)
+		}
+	}
+	return keys
+}
+
+func volumeSortKey(v canonicalVolume) string {
+	raw, _ := json.Marshal(v)
+	return string(raw)
+}
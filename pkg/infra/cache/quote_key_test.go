@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func keyRequest() quote.QuoteRequest {
+	return quote.QuoteRequest{
+		Shipper: quote.Shipper{
+			RegisteredNumber: "25438296000158",
+			Token:            "token-de-teste-com-32-caracteres",
+			PlatformCode:     "5AKVkHqCn",
+		},
+		Recipient: quote.Recipient{Type: 0, Country: "BRA", Zipcode: 1311000},
+		Dispatchers: []quote.Dispatcher{
+			{
+				WarehouseID:      "default",
+				RegisteredNumber: "25438296000158",
+				Zipcode:          49157021,
+				Volumes: []quote.Volume{
+					{Category: "7", Amount: 1, UnitaryWeight: 5, UnitaryPrice: 349, Height: 0.2, Width: 0.2, Length: 0.2},
+					{Category: "7", Amount: 2, UnitaryWeight: 4, UnitaryPrice: 556, Height: 0.4, Width: 0.6, Length: 0.15},
+				},
+			},
+		},
+	}
+}
+
+func TestQuoteCacheKey_Format(t *testing.T) {
+	key := QuoteCacheKey(keyRequest())
+
+	assert.True(t, strings.HasPrefix(key, "quote:v2:01311000:"))
+	assert.Len(t, key, len("quote:v2:01311000:")+64)
+	assert.NotContains(t, key, "token-de-teste-com-32-caracteres")
+}
+
+func TestQuoteCacheKey_IgnoresOrderFormattingAndToken(t *testing.T) {
+	base := QuoteCacheKey(keyRequest())
+
+	reordered := keyRequest()
+	volumes := reordered.Dispatchers[0].Volumes
+	reordered.Dispatchers[0].Volumes = []quote.Volume{volumes[1], volumes[0]}
+	assert.Equal(t, base, QuoteCacheKey(reordered))
+
+	formatted := keyRequest()
+	formatted.Shipper.RegisteredNumber = "25.438.296/0001-58"
+	assert.Equal(t, base, QuoteCacheKey(formatted))
+
+	otherToken := keyRequest()
+	otherToken.Shipper.Token = "00000000000000000000000000000000"
+	assert.Equal(t, base, QuoteCacheKey(otherToken))
+}
+
+func TestQuoteCacheKey_ChangesWithPriceRelevantFields(t *testing.T) {
+	base := QuoteCacheKey(keyRequest())
+
+	changes := map[string]func(r *quote.QuoteRequest){
+		"peso":        func(r *quote.QuoteRequest) { r.Dispatchers[0].Volumes[0].UnitaryWeight = 6 },
+		"altura":      func(r *quote.QuoteRequest) { r.Dispatchers[0].Volumes[0].Height = 0.3 },
+		"largura":     func(r *quote.QuoteRequest) { r.Dispatchers[0].Volumes[0].Width = 0.3 },
+		"comprimento": func(r *quote.QuoteRequest) { r.Dispatchers[0].Volumes[0].Length = 0.3 },
+		"preço":       func(r *quote.QuoteRequest) { r.Dispatchers[0].Volumes[0].UnitaryPrice = 350 },
+		"categoria":   func(r *quote.QuoteRequest) { r.Dispatchers[0].Volumes[0].Category = "8" },
+		"quantidade":  func(r *quote.QuoteRequest) { r.Dispatchers[0].Volumes[0].Amount = 3 },
+		"origem":      func(r *quote.QuoteRequest) { r.Dispatchers[0].Zipcode = 1311000 },
+		"destino":     func(r *quote.QuoteRequest) { r.Recipient.Zipcode = 20040002 },
+		"remetente":   func(r *quote.QuoteRequest) { r.Shipper.PlatformCode = "outro" },
+	}
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			request := keyRequest()
+			change(&request)
+			assert.NotEqual(t, base, QuoteCacheKey(request))
+		})
+	}
+}
+
+func TestQuoteSKUIndexPrefix_DoesNotMatchOtherSKUs(t *testing.T) {
+	request := quote.QuoteRequest{Dispatchers: []quote.Dispatcher{{Volumes: []quote.Volume{{SKU: "A:B"}}}}}
+	keys := QuoteSKUIndexKeys(request, "quote:v2:01311000:abc")
+
+	assert.Len(t, keys, 1)
+	assert.True(t, strings.HasPrefix(keys[0], QuoteSKUIndexPrefix("A:B")))
+	assert.False(t, strings.HasPrefix(keys[0], QuoteSKUIndexPrefix("A")))
+}
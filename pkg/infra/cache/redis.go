@@ -3,11 +3,17 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/redis/go-redis/v9"
+	"strings"
 	"time"
 )
 
+// ErrCacheMiss é devolvido por Get quando a chave não existe ou expirou,
+// independente da implementação.
+var ErrCacheMiss = errors.New("chave não encontrada no cache")
+
 func NewRedisInstance(host, port string) *redis.Client {
 	return redis.NewClient(&redis.Options{
 		Addr: fmt.Sprintf("%s:%s", host, port),
@@ -18,6 +24,9 @@ func NewRedisInstance(host, port string) *redis.Client {
 type IRedisCache interface {
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
 	Get(ctx context.Context, key string) (string, error)
+	Delete(ctx context.Context, keys ...string) error
+	ScanPrefix(ctx context.Context, prefix string) ([]string, error)
+	Ping(ctx context.Context) error
 }
 
 type RedisCache struct {
@@ -37,5 +46,40 @@ func (rc *RedisCache) Set(ctx context.Context, key string, value interface{}, ex
 }
 
 func (rc *RedisCache) Get(ctx context.Context, key string) (string, error) {
-	return rc.client.Get(ctx, key).Result()
+	value, err := rc.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrCacheMiss
+	}
+	return value, err
+}
+
+func (rc *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return rc.client.Del(ctx, keys...).Err()
+}
+
+// ScanPrefix usa SCAN em vez de KEYS para não travar o Redis em bases grandes.
+func (rc *RedisCache) ScanPrefix(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	iter := rc.client.Scan(ctx, 0, escapeGlob(prefix)+"*", 500).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	return keys, iter.Err()
 }
+
+func (rc *RedisCache) Ping(ctx context.Context) error {
+	return rc.client.Ping(ctx).Err()
+}
+
+func (rc *RedisCache) Close() error {
+	return rc.client.Close()
+}
+
+func escapeGlob(value string) string {
+	return globReplacer.Replace(value)
+}
+
+var globReplacer = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
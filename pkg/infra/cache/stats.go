@@ -0,0 +1,22 @@
+package cache
+
+import "time"
+
+// Stats resume o uso do cache de cotações desde que o processo subiu.
+// AvgSavedLatency é quanto o provedor levou, em média, para responder as
+// cotações que foram servidas do cache.
+type Stats struct {
+	Hits            int64
+	StaleHits       int64
+	Misses          int64
+	Errors          int64
+	AvgSavedLatency time.Duration
+}
+
+func (s Stats) HitRate() float64 {
+	total := s.Hits + s.StaleHits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits+s.StaleHits) / float64(total)
+}
@@ -0,0 +1,246 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/cache"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/logging"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultQuoteCacheTTL      = 30 * time.Minute
+	DefaultQuoteMaxStaleness  = 10 * time.Minute
+	DefaultQuotePartialTTL    = time.Minute
+	quoteCacheRefreshDeadline = 30 * time.Second
+)
+
+// CachingConfig controla o cache de cotações. Com StaleWhileRevalidate, uma
+// entrada vencida há menos de MaxStaleness ainda é servida enquanto uma única
+// atualização roda em segundo plano; passado esse limite ela é ignorada.
+// Resultados parciais, em que algum provedor falhou, ficam só PartialTTL e
+// nunca são servidos vencidos.
+type CachingConfig struct {
+	TTL                  time.Duration
+	StaleWhileRevalidate bool
+	MaxStaleness         time.Duration
+	PartialTTL           time.Duration
+	Logger               *slog.Logger
+}
+
+func (c CachingConfig) withDefaults() CachingConfig {
+	c.Logger = logging.OrDefault(c.Logger)
+	if c.TTL <= 0 {
+		c.TTL = DefaultQuoteCacheTTL
+	}
+	if c.MaxStaleness <= 0 {
+		c.MaxStaleness = DefaultQuoteMaxStaleness
+	}
+	if c.PartialTTL <= 0 {
+		c.PartialTTL = DefaultQuotePartialTTL
+	}
+	return c
+}
+
+// cachedOffers é o que vai para o cache: as ofertas e quando foram obtidas,
+// para que a idade da entrada não dependa do TTL do Redis.
+type cachedOffers struct {
+	Offers          []quote.Offer `json:"offers"`
+	StoredAt        time.Time     `json:"stored_at"`
+	UpstreamLatency time.Duration `json:"upstream_latency"`
+	Partial         bool          `json:"partial,omitempty"`
+}
+
+func (e cachedOffers) simulation() *quote.Simulation {
+	return &quote.Simulation{Offers: e.Offers, Cached: true, UpstreamLatency: e.UpstreamLatency}
+}
+
+// CachingQuoteAdapter decora qualquer SimulateQuoteOutPutPort guardando as
+// ofertas no cache pela chave canônica da cotação. Cotações idênticas em
+// andamento compartilham uma única chamada ao provedor. Falhas do cache nunca
+// derrubam a simulação: no pior caso a cotação vai ao provedor.
+type CachingQuoteAdapter struct {
+	provider quote.SimulateQuoteOutPutPort
+	cache    cache.IRedisCache
+	cfg      CachingConfig
+	flights  *flightGroup
+	now      func() time.Time
+
+	hits       atomic.Int64
+	staleHits  atomic.Int64
+	misses     atomic.Int64
+	failures   atomic.Int64
+	savedNanos atomic.Int64
+}
+
+func NewCachingQuoteAdapter(provider quote.SimulateQuoteOutPutPort, quoteCache cache.IRedisCache, cfg CachingConfig) *CachingQuoteAdapter {
+	return &CachingQuoteAdapter{
+		provider: provider,
+		cache:    quoteCache,
+		cfg:      cfg.withDefaults(),
+		flights:  newFlightGroup(),
+		now:      time.Now,
+	}
+}
+
+func (ca *CachingQuoteAdapter) Execute(ctx context.Context, quoteData quote.QuoteRequest) ([]quote.Offer, error) {
+	simulation, err := ca.Simulate(ctx, quoteData)
+	if err != nil {
+		return nil, err
+	}
+	return simulation.Offers, nil
+}
+
+// Simulate é o Execute dizendo de onde vieram as ofertas: num acerto, mesmo
+// vencido, devolve Cached e a latência que o provedor levou quando a entrada
+// foi gravada, para que a cotação não seja contada de novo nas métricas.
+func (ca *CachingQuoteAdapter) Simulate(ctx context.Context, quoteData quote.QuoteRequest) (*quote.Simulation, error) {
+	key := cache.QuoteCacheKey(quoteData)
+	fetch := func(ctx context.Context) ([]quote.Offer, error) {
+		return ca.fetch(ctx, key, quoteData)
+	}
+
+	lookupCtx, span := telemetry.StartSpan(ctx, "cache.lookup")
+	span.SetAttributes(attribute.String("cache.key", key))
+	entry, ok := ca.lookup(lookupCtx, key)
+	if ok {
+		age := ca.now().Sub(entry.StoredAt)
+		if age < ca.ttl(entry.Partial) {
+			span.SetAttributes(attribute.String("cache.result", "hit"))
+			span.End()
+			ca.hits.Add(1)
+			ca.savedNanos.Add(int64(entry.UpstreamLatency))
+			return entry.simulation(), nil
+		}
+		if ca.cfg.StaleWhileRevalidate && !entry.Partial && age < ca.cfg.TTL+ca.cfg.MaxStaleness {
+			span.SetAttributes(attribute.String("cache.result", "stale"))
+			span.End()
+			ca.staleHits.Add(1)
+			ca.savedNanos.Add(int64(entry.UpstreamLatency))
+			ca.flights.Go(ctx, key, quoteCacheRefreshDeadline, func(ctx context.Context) ([]quote.Offer, error) {
+				offers, err := fetch(ctx)
+				if err != nil {
+					ca.cfg.Logger.WarnContext(ctx, "não foi possível atualizar o cache", slog.String("cache_key", key), slog.String("error", err.Error()))
+				}
+				return offers, err
+			})
+			return entry.simulation(), nil
+		}
+	}
+
+	span.SetAttributes(attribute.String("cache.result", "miss"))
+	span.End()
+	ca.misses.Add(1)
+	result, err := ca.flights.Do(ctx, key, fetch)
+	if err != nil {
+		return nil, err
+	}
+	// só quem disparou a chamada foi ao provedor; quem se juntou a ela recebe
+	// as mesmas ofertas e conta como acerto, para não duplicá-las nas métricas
+	return &quote.Simulation{Offers: result.Offers, Cached: result.Shared, UpstreamLatency: result.Latency}, nil
+}
+
+// Wait espera as cotações e atualizações de cache ainda em andamento, para que
+// o encerramento do serviço não feche o cache no meio de uma escrita.
+func (ca *CachingQuoteAdapter) Wait(ctx context.Context) error {
+	return ca.flights.Wait(ctx)
+}
+
+func (ca *CachingQuoteAdapter) fetch(ctx context.Context, key string, quoteData quote.QuoteRequest) ([]quote.Offer, error) {
+	simulation, err := ca.simulate(ctx, quoteData)
+	if err != nil {
+		return nil, err
+	}
+	offers := simulation.Offers
+	// lista vazia costuma ser falha parcial dos provedores; não vale guardar
+	if len(offers) == 0 {
+		return offers, nil
+	}
+	entry := cachedOffers{Offers: offers, StoredAt: ca.now(), UpstreamLatency: simulation.UpstreamLatency, Partial: simulation.Partial}
+	err = ca.cache.Set(ctx, key, entry, ca.expiration(entry.Partial))
+	countCacheOperation("set", err)
+	if err != nil {
+		ca.failures.Add(1)
+		ca.cfg.Logger.WarnContext(ctx, "não foi possível salvar as ofertas no cache", slog.String("cache_key", key), slog.String("error", err.Error()))
+		return offers, nil
+	}
+	for _, indexKey := range cache.QuoteSKUIndexKeys(quoteData, key) {
+		err = ca.cache.Set(ctx, indexKey, key, ca.expiration(entry.Partial))
+		countCacheOperation("set", err)
+		if err != nil {
+			ca.failures.Add(1)
+			ca.cfg.Logger.WarnContext(ctx, "não foi possível salvar o índice de SKU no cache", slog.String("cache_key", indexKey), slog.String("error", err.Error()))
+		}
+	}
+	return offers, nil
+}
+
+// simulate consulta o provedor, sabendo se o resultado é parcial quando ele
+// implementa SimulationOutPutPort.
+func (ca *CachingQuoteAdapter) simulate(ctx context.Context, quoteData quote.QuoteRequest) (*quote.Simulation, error) {
+	if provider, ok := ca.provider.(quote.SimulationOutPutPort); ok {
+		return provider.Simulate(ctx, quoteData)
+	}
+	start := time.Now()
+	offers, err := ca.provider.Execute(ctx, quoteData)
+	if err != nil {
+		return nil, err
+	}
+	return &quote.Simulation{Offers: offers, UpstreamLatency: time.Since(start)}, nil
+}
+
+// ttl é por quanto tempo a entrada é servida como fresca.
+func (ca *CachingQuoteAdapter) ttl(partial bool) time.Duration {
+	if partial {
+		return min(ca.cfg.PartialTTL, ca.cfg.TTL)
+	}
+	return ca.cfg.TTL
+}
+
+// expiration é quanto a entrada fica no cache: o TTL, mais a janela em que
+// ainda pode ser servida vencida, que não vale para resultados parciais.
+func (ca *CachingQuoteAdapter) expiration(partial bool) time.Duration {
+	if ca.cfg.StaleWhileRevalidate && !partial {
+		return ca.cfg.TTL + ca.cfg.MaxStaleness
+	}
+	return ca.ttl(partial)
+}
+
+func (ca *CachingQuoteAdapter) lookup(ctx context.Context, key string) (*cachedOffers, bool) {
+	cached, err := ca.cache.Get(ctx, key)
+	if errors.Is(err, cache.ErrCacheMiss) {
+		telemetry.CacheOperations.WithLabelValues("get", "miss").Inc()
+		return nil, false
+	}
+	if err != nil {
+		telemetry.CacheOperations.WithLabelValues("get", "error").Inc()
+		ca.failures.Add(1)
+		ca.cfg.Logger.WarnContext(ctx, "erro ao consultar o cache", slog.String("cache_key", key), slog.String("error", err.Error()))
+		return nil, false
+	}
+	var entry cachedOffers
+	if err = json.Unmarshal([]byte(cached), &entry); err != nil {
+		telemetry.CacheOperations.WithLabelValues("get", "error").Inc()
+		ca.failures.Add(1)
+		ca.cfg.Logger.WarnContext(ctx, "entrada do cache com json inválido", slog.String("cache_key", key), slog.String("error", err.Error()))
+		return nil, false
+	}
+	telemetry.CacheOperations.WithLabelValues("get", "hit").Inc()
+	return &entry, true
+}
+
+// countCacheOperation conta as operações no cache aqui, e não em cada
+// backend, para que Redis e memória apareçam igualmente nas métricas.
+func countCacheOperation(operation string, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	telemetry.CacheOperations.WithLabelValues(operation, result).Inc()
+}
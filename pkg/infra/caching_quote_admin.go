@@ -0,0 +1,88 @@
+package infra
+
+import (
+	"context"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/cache"
+	"strings"
+	"time"
+)
+
+const cachePurgeBatchSize = 500
+
+func (ca *CachingQuoteAdapter) Stats() cache.Stats {
+	stats := cache.Stats{
+		Hits:      ca.hits.Load(),
+		StaleHits: ca.staleHits.Load(),
+		Misses:    ca.misses.Load(),
+		Errors:    ca.failures.Load(),
+	}
+	if served := stats.Hits + stats.StaleHits; served > 0 {
+		stats.AvgSavedLatency = time.Duration(ca.savedNanos.Load() / served)
+	}
+	return stats
+}
+
+// Inspect devolve o conteúdo bruto de uma entrada. Só chaves de cotação podem
+// ser lidas; qualquer outra responde como inexistente.
+func (ca *CachingQuoteAdapter) Inspect(ctx context.Context, key string) (string, error) {
+	if !strings.HasPrefix(key, cache.QuoteKeyPrefix) {
+		return "", cache.ErrCacheMiss
+	}
+	return ca.cache.Get(ctx, key)
+}
+
+// PurgeZipcode remove as cotações cacheadas para um CEP de destino e devolve
+// quantas entradas foram removidas.
+func (ca *CachingQuoteAdapter) PurgeZipcode(ctx context.Context, zipcode int) (int, error) {
+	keys, err := ca.cache.ScanPrefix(ctx, cache.QuoteZipcodePrefix(zipcode))
+	if err != nil {
+		return 0, err
+	}
+	return len(keys), ca.deleteKeys(ctx, keys)
+}
+
+// PurgeSKU remove as cotações que tinham o SKU em algum volume, junto com os
+// índices que apontam para elas.
+func (ca *CachingQuoteAdapter) PurgeSKU(ctx context.Context, sku string) (int, error) {
+	prefix := cache.QuoteSKUIndexPrefix(sku)
+	indexKeys, err := ca.cache.ScanPrefix(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+	keys := make([]string, 0, len(indexKeys)*2)
+	for _, indexKey := range indexKeys {
+		keys = append(keys, strings.TrimPrefix(indexKey, prefix))
+	}
+	if err = ca.deleteKeys(ctx, append(keys, indexKeys...)); err != nil {
+		return 0, err
+	}
+	return len(indexKeys), nil
+}
+
+// Flush remove todas as chaves de cotação da versão atual. O total devolvido
+// não conta os índices por SKU.
+func (ca *CachingQuoteAdapter) Flush(ctx context.Context) (int, error) {
+	keys, err := ca.cache.ScanPrefix(ctx, cache.QuoteKeyPrefix)
+	if err != nil {
+		return 0, err
+	}
+	entries := 0
+	for _, key := range keys {
+		if !cache.IsQuoteSKUIndexKey(key) {
+			entries++
+		}
+	}
+	return entries, ca.deleteKeys(ctx, keys)
+}
+
+func (ca *CachingQuoteAdapter) deleteKeys(ctx context.Context, keys []string) error {
+	for start := 0; start < len(keys); start += cachePurgeBatchSize {
+		end := min(start+cachePurgeBatchSize, len(keys))
+		err := ca.cache.Delete(ctx, keys[start:end]...)
+		countCacheOperation("delete", err)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package infra
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// ErrCircuitOpen identifica, com errors.Is, as chamadas recusadas pelo breaker.
+var ErrCircuitOpen = errors.New("circuit breaker aberto")
+
+// CircuitOpenError é retornado sem chamar o upstream enquanto o circuito está
+// aberto, ou em half-open enquanto a chamada de teste não termina.
+type CircuitOpenError struct {
+	Name       string
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	if e.RetryAfter <= 0 {
+		return fmt.Sprintf("circuit breaker %s em half-open, aguardando a chamada de teste", e.Name)
+	}
+	return fmt.Sprintf("circuit breaker %s aberto, nova tentativa em %s", e.Name, e.RetryAfter.Round(time.Second))
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+type CircuitBreakerStats struct {
+	State               CircuitState
+	ConsecutiveFailures int
+	TotalOpens          int
+	TotalRejected       int
+}
+
+// CircuitBreaker abre após failureThreshold falhas consecutivas e, passado
+// openTimeout, deixa uma única chamada de teste passar em half-open; as demais
+// continuam recusadas até ela terminar. Um sucesso fecha o circuito e uma
+// falha o abre novamente.
+type CircuitBreaker struct {
+	mu               sync.Mutex
+	name             string
+	failureThreshold int
+	openTimeout      time.Duration
+	state            CircuitState
+	failures         int
+	openedAt         time.Time
+	opens            int
+	rejected         int
+	probing          bool
+	now              func() time.Time
+	logger           *slog.Logger
+	// onStateChange, quando definido, é chamado a cada transição com o lock
+	// do breaker seguro; não pode chamar métodos do breaker.
+	onStateChange func(from, to CircuitState)
+}
+
+func NewCircuitBreaker(name string, failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		name:             name,
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		now:              time.Now,
+		logger:           slog.Default(),
+	}
+}
+
+func (cb *CircuitBreaker) Allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	switch cb.state {
+	case CircuitHalfOpen:
+		if cb.probing {
+			cb.rejected++
+			return &CircuitOpenError{Name: cb.name}
+		}
+		cb.probing = true
+		return nil
+	case CircuitOpen:
+		elapsed := cb.now().Sub(cb.openedAt)
+		if elapsed >= cb.openTimeout {
+			cb.transition(CircuitHalfOpen)
+			cb.probing = true
+			return nil
+		}
+		cb.rejected++
+		return &CircuitOpenError{Name: cb.name, RetryAfter: cb.openTimeout - elapsed}
+	}
+	return nil
+}
+
+// Cancel libera a chamada de teste do half-open quando ela termina sem
+// resultado, por exemplo porque o cliente desistiu, para que a próxima
+// chamada possa testar o upstream.
+func (cb *CircuitBreaker) Cancel() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.probing = false
+}
+
+func (cb *CircuitBreaker) Success() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.failures = 0
+	cb.probing = false
+	if cb.state != CircuitClosed {
+		cb.transition(CircuitClosed)
+	}
+}
+
+func (cb *CircuitBreaker) Failure() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.failures++
+	cb.probing = false
+	if cb.state == CircuitHalfOpen || (cb.state == CircuitClosed && cb.failures >= cb.failureThreshold) {
+		cb.openedAt = cb.now()
+		cb.opens++
+		cb.transition(CircuitOpen)
+	}
+}
+
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
+
+func (cb *CircuitBreaker) Stats() CircuitBreakerStats {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return CircuitBreakerStats{
+		State:               cb.state,
+		ConsecutiveFailures: cb.failures,
+		TotalOpens:          cb.opens,
+		TotalRejected:       cb.rejected,
+	}
+}
+
+func (cb *CircuitBreaker) transition(to CircuitState) {
+	cb.logger.Warn("circuit breaker mudou de estado",
+		slog.String("breaker", cb.name),
+		slog.String("from", cb.state.String()),
+		slog.String("to", to.String()),
+		slog.Int("consecutive_failures", cb.failures),
+	)
+	if cb.onStateChange != nil {
+		cb.onStateChange(cb.state, to)
+	}
+	cb.state = to
+}
@@ -1,8 +1,17 @@
 package database
 
-import "github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
+import (
+	"context"
+	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
+)
 
 type IQuoteRepository interface {
-	SaveAllOffers(offers []quote.Offer) error
-	GetMetricsQuotes(lastQuotes int) (*quote.Metrics, error)
+	SaveQuote(ctx context.Context, quoteData *quote.Quote) error
+	GetQuote(ctx context.Context, id string) (*quote.Quote, error)
+	ListQuotes(ctx context.Context, filter quote.QuoteFilter) (*quote.QuotePage, error)
+	GetMetricsQuotes(ctx context.Context, filter quote.MetricsFilter) (*quote.Metrics, error)
+}
+
+type IWarehouseRepository interface {
+	ListWarehouses(ctx context.Context) ([]quote.Warehouse, error)
 }
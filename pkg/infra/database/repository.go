@@ -1,106 +1,355 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/lib/pq"
 	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/logging"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"log/slog"
 	"strings"
+	"time"
 )
 
 type QuoteRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	logger *slog.Logger
 }
 
-func NewQuoteRepository(db *sql.DB) *QuoteRepository {
-	return &QuoteRepository{db: db}
+func NewQuoteRepository(db *sql.DB, logger *slog.Logger) *QuoteRepository {
+	return &QuoteRepository{db: db, logger: logging.OrDefault(logger)}
 }
 
-func (q *QuoteRepository) GetMetricsQuotes(lastQuotes int) (*quote.Metrics, error) {
-	var queryBuilder strings.Builder
-	var from string
-	if lastQuotes > 0 {
-		queryBuilder.WriteString(`
-		with limited_offers as (
-			select * from offers order by created_at desc limit $1
-		)
-		`)
-		from = "limited_offers"
-	} else {
-		from = "offers"
-	}
-
-	queryBuilder.WriteString(fmt.Sprintf(`
-		select
+// carrierAggregates são as colunas por transportadora comuns ao total e à
+// série por período, na ordem lida por scanCarrierMetrics. Percentis usam
+// interpolação (percentile_cont) e o preço por kg ignora ofertas sem peso.
+const carrierAggregates = `
 			carrier,
 			count(*) as total_offer,
 			round(sum(final_price),2) as total_price,
 			round(avg(final_price),2) as avg_price,
 			round(min(final_price),2) as min_price,
 			round(max(final_price),2) as max_price,
-			round((select min(final_price) from %s)) as min_general_price,
-			round((select max(final_price) from %s)) as max_general_price,
-			round((select avg(final_price) from %s)) as avg_general_price,
-    		(select carrier from %s where final_price = (select min(final_price) from %s) limit 1) AS carrier_min_general_price,
-    		(select carrier from %s where final_price = (select max(final_price) from %s) limit 1) AS carrier_max_general_price
-		from %s group by carrier`, from, from, from, from, from, from, from, from))
-
-	query := queryBuilder.String()
-	stmt, err := q.db.Prepare(query)
+			round(percentile_cont(0.5) within group (order by final_price)::numeric,2) as p50_price,
+			round(percentile_cont(0.9) within group (order by final_price)::numeric,2) as p90_price,
+			round(percentile_cont(0.99) within group (order by final_price)::numeric,2) as p99_price,
+			round(stddev_pop(final_price),2) as stddev_price,
+			coalesce(round(avg(final_price / nullif(weight, 0)),2), 0) as avg_price_per_kg,
+			round(avg(delivery_time),2) as avg_delivery_time,
+			min(delivery_time) as min_delivery_time,
+			max(delivery_time) as max_delivery_time`
+
+func scanCarrierMetrics(row rowScanner, carrierMetrics *quote.CarrierMetrics, extra ...any) error {
+	dest := append(extra,
+		&carrierMetrics.Name,
+		&carrierMetrics.TotalOffer,
+		&carrierMetrics.TotalPrice,
+		&carrierMetrics.AvgPrice,
+		&carrierMetrics.MinPrice,
+		&carrierMetrics.MaxPrice,
+		&carrierMetrics.P50Price,
+		&carrierMetrics.P90Price,
+		&carrierMetrics.P99Price,
+		&carrierMetrics.StdDevPrice,
+		&carrierMetrics.AvgPricePerKg,
+		&carrierMetrics.AvgDeliveryTime,
+		&carrierMetrics.MinDeliveryTime,
+		&carrierMetrics.MaxDeliveryTime,
+	)
+	return row.Scan(dest...)
+}
+
+func (q *QuoteRepository) GetMetricsQuotes(ctx context.Context, filter quote.MetricsFilter) (*quote.Metrics, error) {
+	filtered, args := filteredOffersCTE(filter)
+	var metrics quote.Metrics
+
+	err := q.db.QueryRowContext(ctx, filtered+`
+		select
+			coalesce(round(min(final_price),2), 0) as min_general_price,
+			coalesce(round(max(final_price),2), 0) as max_general_price,
+			coalesce(round(avg(final_price),2), 0) as avg_general_price,
+			coalesce((select carrier from filtered_offers where final_price = (select min(final_price) from filtered_offers) limit 1), '') as carrier_min_general_price,
+			coalesce((select carrier from filtered_offers where final_price = (select max(final_price) from filtered_offers) limit 1), '') as carrier_max_general_price
+		from filtered_offers`, args...).Scan(
+		&metrics.GeneralMinPrice,
+		&metrics.GeneralMaxPrice,
+		&metrics.GeneralAvgPrice,
+		&metrics.GeneralMinCarrierName,
+		&metrics.GeneralMaxCarrierName,
+	)
 	if err != nil {
 		return nil, err
 	}
-	defer stmt.Close()
-	var rows *sql.Rows
-	if lastQuotes > 0 {
-		rows, err = stmt.Query(lastQuotes)
-		if err != nil {
+
+	rows, err := q.db.QueryContext(ctx, filtered+`
+		select`+carrierAggregates+`
+		from filtered_offers group by carrier order by carrier`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var carrierMetrics quote.CarrierMetrics
+		if err = scanCarrierMetrics(rows, &carrierMetrics); err != nil {
+			return nil, err
+		}
+		metrics.Carrier = append(metrics.Carrier, carrierMetrics)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if filter.GroupBy != "" {
+		if metrics.Series, err = q.getMetricsSeries(ctx, filter); err != nil {
+			return nil, err
+		}
+	}
+	if filter.RegionBy != "" {
+		if metrics.Regions, err = q.getRegionMetrics(ctx, filter); err != nil {
 			return nil, err
 		}
-	} else {
-		rows, err = stmt.Query()
+	}
+	return &metrics, nil
+}
+
+// getRegionMetrics agrega por região de destino e transportadora. Só entram
+// ofertas ligadas a uma cotação, que é onde o CEP de destino fica gravado;
+// CEPs fora da tabela cep_ranges caem na região "desconhecida".
+func (q *QuoteRepository) getRegionMetrics(ctx context.Context, filter quote.MetricsFilter) ([]quote.RegionMetrics, error) {
+	filtered, args := filteredOffersCTE(filter)
+	var region, join string
+	switch filter.RegionBy {
+	case quote.RegionByUF:
+		region = "coalesce(r.uf, 'desconhecida')"
+		join = "left join cep_ranges r on q.recipient_zipcode between r.start_cep and r.end_cep"
+	case quote.RegionByRegion:
+		region = "coalesce(r.region, 'desconhecida')"
+		join = "left join cep_ranges r on q.recipient_zipcode between r.start_cep and r.end_cep"
+	case quote.RegionByCEPPrefix:
+		args = append(args, filter.CEPPrefixLength)
+		region = fmt.Sprintf("left(lpad(q.recipient_zipcode::text, 8, '0'), $%d)", len(args))
+	default:
+		return nil, fmt.Errorf("agrupamento por região desconhecido: %s", filter.RegionBy)
+	}
+
+	rows, err := q.db.QueryContext(ctx, filtered+fmt.Sprintf(`
+		select
+			%s as region,
+			o.carrier,
+			count(*) as total_offer,
+			round(avg(o.final_price),2) as avg_price,
+			round(avg(o.delivery_time),2) as avg_delivery_time
+		from filtered_offers o
+		join quotes q on q.id = o.quote_id
+		%s
+		group by 1, o.carrier order by 1, o.carrier`, region, join), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var regions []quote.RegionMetrics
+	var currentRegion string
+	var carriers []quote.RegionCarrierMetrics
+	for rows.Next() {
+		var rowRegion string
+		var carrierMetrics quote.RegionCarrierMetrics
+		err = rows.Scan(&rowRegion, &carrierMetrics.Name, &carrierMetrics.TotalOffer, &carrierMetrics.AvgPrice, &carrierMetrics.AvgDeliveryTime)
 		if err != nil {
 			return nil, err
 		}
+		if rowRegion != currentRegion && carriers != nil {
+			regions = append(regions, quote.NewRegionMetrics(currentRegion, carriers))
+			carriers = nil
+		}
+		currentRegion = rowRegion
+		carriers = append(carriers, carrierMetrics)
 	}
-	var metrics quote.Metrics
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if carriers != nil {
+		regions = append(regions, quote.NewRegionMetrics(currentRegion, carriers))
+	}
+	return regions, nil
+}
+
+// getMetricsSeries agrega por período (em UTC) e transportadora, em ordem
+// cronológica.
+func (q *QuoteRepository) getMetricsSeries(ctx context.Context, filter quote.MetricsFilter) ([]quote.MetricsBucket, error) {
+	filtered, args := filteredOffersCTE(filter)
+	args = append(args, string(filter.GroupBy))
+	query := filtered + fmt.Sprintf(`
+		select
+			date_trunc($%d, created_at at time zone 'UTC') as period_start,`+carrierAggregates+`
+		from filtered_offers group by period_start, carrier order by period_start, carrier`, len(args))
 
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var series []quote.MetricsBucket
 	for rows.Next() {
+		var periodStart time.Time
 		var carrierMetrics quote.CarrierMetrics
-		err = rows.Scan(&carrierMetrics.Name,
-			&carrierMetrics.TotalOffer,
-			&carrierMetrics.TotalPrice,
-			&carrierMetrics.AvgPrice,
-			&carrierMetrics.MinPrice,
-			&carrierMetrics.MaxPrice,
-			&metrics.GeneralMinPrice,
-			&metrics.GeneralMaxPrice,
-			&metrics.GeneralAvgPrice,
-			&metrics.GeneralMinCarrierName,
-			&metrics.GeneralMaxCarrierName,
-		)
-		if err != nil {
+		if err = scanCarrierMetrics(rows, &carrierMetrics, &periodStart); err != nil {
 			return nil, err
 		}
-		metrics.Carrier = append(metrics.Carrier, carrierMetrics)
+		periodStart = periodStart.UTC()
+		if len(series) == 0 || !series[len(series)-1].PeriodStart.Equal(periodStart) {
+			series = append(series, quote.MetricsBucket{PeriodStart: periodStart})
+		}
+		last := &series[len(series)-1]
+		last.Carrier = append(last.Carrier, carrierMetrics)
 	}
+	return series, rows.Err()
+}
 
-	return &metrics, nil
+// filteredOffersCTE monta o "with filtered_offers as (...)" com os filtros de
+// período, transportadora e serviço, limitado às LastQuotes ofertas mais
+// recentes quando informado. Ofertas de cotações servidas do cache repetem as
+// da cotação original e nunca entram.
+func filteredOffersCTE(filter quote.MetricsFilter) (string, []any) {
+	conditions := []string{"not exists (select 1 from quotes cq where cq.id = offers.quote_id and cq.cached)"}
+	var args []any
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "created_at >= "+arg(filter.From))
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "created_at <= "+arg(filter.To))
+	}
+	if filter.Carrier != "" {
+		conditions = append(conditions, "lower(carrier) = lower("+arg(filter.Carrier)+")")
+	}
+	if filter.Service != "" {
+		conditions = append(conditions, "lower(service) = lower("+arg(filter.Service)+")")
+	}
+
+	var queryBuilder strings.Builder
+	queryBuilder.WriteString("\n\t\twith filtered_offers as (\n\t\t\tselect * from offers")
+	queryBuilder.WriteString(" where " + strings.Join(conditions, " and "))
+	if filter.LastQuotes > 0 {
+		queryBuilder.WriteString(" order by created_at desc limit " + arg(filter.LastQuotes))
+	}
+	queryBuilder.WriteString("\n\t\t)")
+	return queryBuilder.String(), args
+}
+
+type originRow struct {
+	WarehouseID      string `json:"warehouse_id"`
+	RegisteredNumber string `json:"registered_number"`
+	Zipcode          int    `json:"zipcode"`
+}
+
+type volumeRow struct {
+	WarehouseID   string  `json:"warehouse_id"`
+	SKU           string  `json:"sku,omitempty"`
+	Category      string  `json:"category"`
+	Amount        int     `json:"amount"`
+	UnitaryWeight float64 `json:"unitary_weight"`
+	UnitaryPrice  float64 `json:"unitary_price"`
+	Height        float64 `json:"height"`
+	Width         float64 `json:"width"`
+	Length        float64 `json:"length"`
+}
+
+func (q *QuoteRepository) SaveQuote(ctx context.Context, quoteData *quote.Quote) error {
+	ctx, span := telemetry.StartSpan(ctx, "QuoteRepository.SaveQuote")
+	defer span.End()
+	span.SetAttributes(attribute.String("quote.id", quoteData.ID), attribute.Int("quote.offers", len(quoteData.Offers)))
+
+	start := time.Now()
+	err := q.saveQuote(ctx, quoteData)
+	telemetry.DBWriteDuration.WithLabelValues("save_quote").Observe(time.Since(start).Seconds())
+	latency := slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000)
+	if err != nil {
+		telemetry.DBWriteFailures.WithLabelValues("save_quote").Inc()
+		telemetry.RecordError(span, err)
+		q.logger.ErrorContext(ctx, "erro ao gravar a cotação", slog.String("quote_id", quoteData.ID), latency, slog.String("error", err.Error()))
+		return err
+	}
+	q.logger.DebugContext(ctx, "cotação gravada", slog.String("quote_id", quoteData.ID), slog.Int("offers", len(quoteData.Offers)), latency)
+	return nil
 }
 
-func (q *QuoteRepository) SaveAllOffers(offers []quote.Offer) error {
-	tx, err := q.db.Begin()
+func (q *QuoteRepository) saveQuote(ctx context.Context, quoteData *quote.Quote) error {
+	var origins []originRow
+	var volumes []volumeRow
+	for _, d := range quoteData.Request.Dispatchers {
+		origins = append(origins, originRow{
+			WarehouseID:      d.WarehouseID,
+			RegisteredNumber: d.RegisteredNumber,
+			Zipcode:          d.Zipcode,
+		})
+		for _, v := range d.Volumes {
+			volumes = append(volumes, volumeRow{
+				WarehouseID:   d.WarehouseID,
+				SKU:           v.SKU,
+				Category:      v.Category,
+				Amount:        v.Amount,
+				UnitaryWeight: v.UnitaryWeight,
+				UnitaryPrice:  v.UnitaryPrice,
+				Height:        v.Height,
+				Width:         v.Width,
+				Length:        v.Length,
+			})
+		}
+	}
+	originJSON, err := json.Marshal(origins)
+	if err != nil {
+		return err
+	}
+	volumesJSON, err := json.Marshal(volumes)
 	if err != nil {
 		return err
 	}
 
-	stmt, err := tx.Prepare("INSERT INTO offers(final_price, carrier, service, delivery_time) VALUES ($1, $2, $3, $4)")
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO quotes(id, request_id, recipient_type, recipient_zipcode, recipient_registered_number, origin, volumes, total_weight, declared_value, upstream_latency_ms, status, cached, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		quoteData.ID,
+		sql.NullString{String: quoteData.RequestID, Valid: quoteData.RequestID != ""},
+		quoteData.Request.Recipient.Type,
+		quoteData.Request.Recipient.Zipcode,
+		sql.NullString{String: quoteData.Request.Recipient.RegisteredNumber, Valid: quoteData.Request.Recipient.RegisteredNumber != ""},
+		originJSON,
+		volumesJSON,
+		quoteData.TotalWeight(),
+		quoteData.DeclaredValue(),
+		quoteData.UpstreamLatency.Milliseconds(),
+		string(quoteData.Status),
+		quoteData.Cached,
+		quoteData.CreatedAt,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, "INSERT INTO offers(quote_id, final_price, carrier, service, delivery_time, source, warehouse_id, weight, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)")
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
 	defer stmt.Close()
-	for _, offer := range offers {
-		_, err := stmt.Exec(offer.FinalPrice, offer.Carrier, offer.Service, offer.DeliveryTime)
+	for _, offer := range quoteData.Offers {
+		_, err := stmt.ExecContext(ctx, quoteData.ID, offer.FinalPrice, offer.Carrier, offer.Service, offer.DeliveryTime, offer.Source, offer.WarehouseID, quoteData.OfferWeight(offer), quoteData.CreatedAt)
 		if err != nil {
 			tx.Rollback()
 			return err
@@ -109,3 +358,199 @@ func (q *QuoteRepository) SaveAllOffers(offers []quote.Offer) error {
 	}
 	return tx.Commit()
 }
+
+const quoteColumns = "id, request_id, recipient_type, recipient_zipcode, recipient_registered_number, origin, volumes, upstream_latency_ms, status, cached, created_at"
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanQuote(row rowScanner) (*quote.Quote, error) {
+	var quoteData quote.Quote
+	var originJSON, volumesJSON []byte
+	var status string
+	var latencyMs int64
+	var requestID, recipientRegisteredNumber sql.NullString
+	err := row.Scan(
+		&quoteData.ID,
+		&requestID,
+		&quoteData.Request.Recipient.Type,
+		&quoteData.Request.Recipient.Zipcode,
+		&recipientRegisteredNumber,
+		&originJSON,
+		&volumesJSON,
+		&latencyMs,
+		&status,
+		&quoteData.Cached,
+		&quoteData.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	quoteData.RequestID = requestID.String
+	quoteData.Status = quote.QuoteStatus(status)
+	quoteData.UpstreamLatency = time.Duration(latencyMs) * time.Millisecond
+	quoteData.Request.Recipient.Country = "BRA"
+	quoteData.Request.Recipient.RegisteredNumber = recipientRegisteredNumber.String
+	if err = unmarshalDispatchers(originJSON, volumesJSON, &quoteData.Request); err != nil {
+		return nil, err
+	}
+	return &quoteData, nil
+}
+
+func (q *QuoteRepository) GetQuote(ctx context.Context, id string) (*quote.Quote, error) {
+	quoteData, err := scanQuote(q.db.QueryRowContext(ctx, "SELECT "+quoteColumns+" FROM quotes WHERE id = $1", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, quote.ErrQuoteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = q.loadOffers(ctx, []*quote.Quote{quoteData}); err != nil {
+		return nil, err
+	}
+	return quoteData, nil
+}
+
+func (q *QuoteRepository) ListQuotes(ctx context.Context, filter quote.QuoteFilter) (*quote.QuotePage, error) {
+	var conditions []string
+	var args []any
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "q.created_at >= "+arg(filter.From))
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "q.created_at <= "+arg(filter.To))
+	}
+	if filter.RecipientZipcode > 0 {
+		conditions = append(conditions, "q.recipient_zipcode = "+arg(filter.RecipientZipcode))
+	}
+	var offerConditions []string
+	if filter.Carrier != "" {
+		offerConditions = append(offerConditions, "lower(o.carrier) = lower("+arg(filter.Carrier)+")")
+	}
+	if filter.MinPrice != nil {
+		offerConditions = append(offerConditions, "o.final_price >= "+arg(*filter.MinPrice))
+	}
+	if filter.MaxPrice != nil {
+		offerConditions = append(offerConditions, "o.final_price <= "+arg(*filter.MaxPrice))
+	}
+	if len(offerConditions) > 0 {
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM offers o WHERE o.quote_id = q.id AND %s)",
+			strings.Join(offerConditions, " AND ")))
+	}
+	if filter.After != nil {
+		conditions = append(conditions, fmt.Sprintf("(q.created_at, q.id) < (%s, %s)", arg(filter.After.CreatedAt), arg(filter.After.ID)))
+	}
+
+	var queryBuilder strings.Builder
+	queryBuilder.WriteString("SELECT q." + strings.ReplaceAll(quoteColumns, ", ", ", q.") + " FROM quotes q")
+	if len(conditions) > 0 {
+		queryBuilder.WriteString(" WHERE " + strings.Join(conditions, " AND "))
+	}
+	// busca um registro a mais para saber se existe próxima página
+	queryBuilder.WriteString(" ORDER BY q.created_at DESC, q.id DESC LIMIT " + arg(filter.Limit+1))
+
+	rows, err := q.db.QueryContext(ctx, queryBuilder.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var quotes []*quote.Quote
+	for rows.Next() {
+		quoteData, err := scanQuote(rows)
+		if err != nil {
+			return nil, err
+		}
+		quotes = append(quotes, quoteData)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var page quote.QuotePage
+	if len(quotes) > filter.Limit {
+		quotes = quotes[:filter.Limit]
+		last := quotes[len(quotes)-1]
+		page.NextCursor = quote.QuoteCursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	if err = q.loadOffers(ctx, quotes); err != nil {
+		return nil, err
+	}
+	for _, quoteData := range quotes {
+		page.Quotes = append(page.Quotes, *quoteData)
+	}
+	return &page, nil
+}
+
+func (q *QuoteRepository) loadOffers(ctx context.Context, quotes []*quote.Quote) error {
+	if len(quotes) == 0 {
+		return nil
+	}
+	ids := make([]string, len(quotes))
+	byID := make(map[string]*quote.Quote, len(quotes))
+	for i, quoteData := range quotes {
+		ids[i] = quoteData.ID
+		byID[quoteData.ID] = quoteData
+	}
+
+	rows, err := q.db.QueryContext(ctx, `
+		SELECT quote_id, final_price, carrier, service, delivery_time, coalesce(source, ''), coalesce(warehouse_id, '')
+		FROM offers WHERE quote_id = ANY($1) ORDER BY id`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var quoteID string
+		var offer quote.Offer
+		err = rows.Scan(&quoteID, &offer.FinalPrice, &offer.Carrier, &offer.Service, &offer.DeliveryTime, &offer.Source, &offer.WarehouseID)
+		if err != nil {
+			return err
+		}
+		byID[quoteID].Offers = append(byID[quoteID].Offers, offer)
+	}
+	return rows.Err()
+}
+
+func unmarshalDispatchers(originJSON, volumesJSON []byte, request *quote.QuoteRequest) error {
+	var origins []originRow
+	if err := json.Unmarshal(originJSON, &origins); err != nil {
+		return err
+	}
+	var volumes []volumeRow
+	if err := json.Unmarshal(volumesJSON, &volumes); err != nil {
+		return err
+	}
+	dispatcherIndex := make(map[string]int, len(origins))
+	for i, o := range origins {
+		dispatcherIndex[o.WarehouseID] = i
+		request.Dispatchers = append(request.Dispatchers, quote.Dispatcher{
+			WarehouseID:      o.WarehouseID,
+			RegisteredNumber: o.RegisteredNumber,
+			Zipcode:          o.Zipcode,
+		})
+	}
+	for _, v := range volumes {
+		i, ok := dispatcherIndex[v.WarehouseID]
+		if !ok {
+			continue
+		}
+		request.Dispatchers[i].Volumes = append(request.Dispatchers[i].Volumes, quote.Volume{
+			SKU:           v.SKU,
+			Category:      v.Category,
+			Amount:        v.Amount,
+			UnitaryWeight: v.UnitaryWeight,
+			UnitaryPrice:  v.UnitaryPrice,
+			Height:        v.Height,
+			Width:         v.Width,
+			Length:        v.Length,
+		})
+	}
+	return nil
+}
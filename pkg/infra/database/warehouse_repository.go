@@ -0,0 +1,42 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
+)
+
+type WarehouseRepository struct {
+	db *sql.DB
+}
+
+func NewWarehouseRepository(db *sql.DB) *WarehouseRepository {
+	return &WarehouseRepository{db: db}
+}
+
+func (w *WarehouseRepository) ListWarehouses(ctx context.Context) ([]quote.Warehouse, error) {
+	rows, err := w.db.QueryContext(ctx, "SELECT id, name, registered_number, zipcode, active FROM warehouses ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var warehouses []quote.Warehouse
+	for rows.Next() {
+		var warehouse quote.Warehouse
+		err = rows.Scan(&warehouse.ID,
+			&warehouse.Name,
+			&warehouse.RegisteredNumber,
+			&warehouse.Zipcode,
+			&warehouse.Active,
+		)
+		if err != nil {
+			return nil, err
+		}
+		warehouses = append(warehouses, warehouse)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return warehouses, nil
+}
@@ -0,0 +1,140 @@
+package infra
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
+)
+
+type flightCall struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+	offers  []quote.Offer
+	latency time.Duration
+	err     error
+}
+
+// flightResult é o que cada chamador de Do recebe. Latency é quanto a chamada
+// compartilhada levou; Shared indica que quem chamou se juntou a uma chamada
+// disparada por outro e não foi ao provedor.
+type flightResult struct {
+	Offers  []quote.Offer
+	Latency time.Duration
+	Shared  bool
+}
+
+// flightGroup junta chamadas idênticas em andamento: enquanto uma cotação
+// para a chave está no provedor, as demais esperam pelo mesmo resultado.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+	wg    sync.WaitGroup
+}
+
+func newFlightGroup() *flightGroup {
+	return &flightGroup{calls: make(map[string]*flightCall)}
+}
+
+// Do executa fn uma única vez por chave em andamento. Como o resultado é
+// compartilhado, fn não recebe o ctx de quem chamou: recebe um contexto com o
+// prazo do primeiro chamador, cancelado quando o último que espera desiste.
+// Cada chamador desiste ao ter o próprio ctx cancelado.
+func (g *flightGroup) Do(ctx context.Context, key string, fn func(ctx context.Context) ([]quote.Offer, error)) (*flightResult, error) {
+	g.mu.Lock()
+	call, shared := g.calls[key]
+	if !shared {
+		sharedCtx, cancel := sharedContext(ctx)
+		call = g.start(sharedCtx, cancel, key, fn)
+	}
+	call.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		if call.err != nil {
+			return nil, call.err
+		}
+		return &flightResult{Offers: call.offers, Latency: call.latency, Shared: shared}, nil
+	case <-ctx.Done():
+		g.leave(call)
+		return nil, ctx.Err()
+	}
+}
+
+// Go dispara fn em segundo plano, limitada a timeout, a menos que já exista
+// uma chamada em andamento para a chave. A própria atualização conta como
+// alguém esperando, então quem se juntar a ela com Do e desistir não a
+// cancela; só o timeout a interrompe.
+func (g *flightGroup) Go(ctx context.Context, key string, timeout time.Duration, fn func(ctx context.Context) ([]quote.Offer, error)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.calls[key]; ok {
+		return
+	}
+	refreshCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	call := g.start(refreshCtx, cancel, key, fn)
+	call.waiters++
+}
+
+// start registra a chamada e a dispara; precisa ser chamado com g.mu travado.
+func (g *flightGroup) start(ctx context.Context, cancel context.CancelFunc, key string, fn func(ctx context.Context) ([]quote.Offer, error)) *flightCall {
+	call := &flightCall{done: make(chan struct{}), cancel: cancel}
+	g.calls[key] = call
+	g.wg.Add(1)
+	go g.run(ctx, key, call, fn)
+	return call
+}
+
+// leave tira um chamador da espera e cancela a chamada quando não sobra
+// ninguém esperando por ela.
+func (g *flightGroup) leave(call *flightCall) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	call.waiters--
+	if call.waiters == 0 {
+		call.cancel()
+	}
+}
+
+// Wait espera as chamadas em andamento terminarem, inclusive as atualizações
+// em segundo plano, ou até ctx ser cancelado.
+func (g *flightGroup) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (g *flightGroup) run(ctx context.Context, key string, call *flightCall, fn func(ctx context.Context) ([]quote.Offer, error)) {
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		call.cancel()
+		close(call.done)
+		g.wg.Done()
+	}()
+	start := time.Now()
+	call.offers, call.err = fn(ctx)
+	call.latency = time.Since(start)
+}
+
+// sharedContext desliga ctx do cancelamento de quem chamou, mas mantém o
+// prazo dele, para que a chamada compartilhada não fique sem limite.
+func sharedContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	shared := context.WithoutCancel(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(shared, deadline)
+	}
+	return context.WithCancel(shared)
+}
@@ -2,53 +2,293 @@ package infra
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/database"
 	http2 "github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/http"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/logging"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 	"io"
+	"log/slog"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
 )
 
+const (
+	freteRapidoProvider    = "frete_rapido"
+	freteRapidoSimulateURL = "https://sp.freterapido.com/api/v3/quote/simulate"
+)
+
+type FreteRapidoConfig struct {
+	Timeout          time.Duration
+	Retry            RetryPolicy
+	BreakerThreshold int
+	BreakerTimeout   time.Duration
+	Logger           *slog.Logger
+}
+
+func (c FreteRapidoConfig) withDefaults() FreteRapidoConfig {
+	c.Logger = logging.OrDefault(c.Logger)
+	if c.Timeout <= 0 {
+		c.Timeout = 15 * time.Second
+	}
+	if c.Retry.MaxRetries < 0 {
+		c.Retry.MaxRetries = 0
+	}
+	if c.Retry.BaseDelay <= 0 {
+		c.Retry.BaseDelay = 200 * time.Millisecond
+	}
+	if c.Retry.MaxDelay <= 0 {
+		c.Retry.MaxDelay = 2 * time.Second
+	}
+	if c.BreakerThreshold <= 0 {
+		c.BreakerThreshold = 5
+	}
+	if c.BreakerTimeout <= 0 {
+		c.BreakerTimeout = 30 * time.Second
+	}
+	return c
+}
+
+// UpstreamStatusError representa uma resposta diferente de 200 da Frete Rápido.
+// RetryAfter vem do cabeçalho Retry-After, quando presente.
+type UpstreamStatusError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *UpstreamStatusError) Error() string {
+	return fmt.Sprintf("frete Rapido Contract returned %s", e.Body)
+}
+
 type FreteRapidoAdapter struct {
-	client *http.Client
-	repo   database.IQuoteRepository
+	client  *http.Client
+	retry   RetryPolicy
+	breaker *CircuitBreaker
+	logger  *slog.Logger
 }
 
-func NewFreteRapidoAdapter(repo database.IQuoteRepository) *FreteRapidoAdapter {
+func NewFreteRapidoAdapter(cfg FreteRapidoConfig) *FreteRapidoAdapter {
+	cfg = cfg.withDefaults()
+	breaker := NewCircuitBreaker(freteRapidoProvider, cfg.BreakerThreshold, cfg.BreakerTimeout)
+	breaker.logger = cfg.Logger
+	breaker.onStateChange = func(from, to CircuitState) {
+		telemetry.FreteRapidoCircuitState.Set(float64(to))
+		if to == CircuitOpen {
+			telemetry.FreteRapidoCircuitOpens.Inc()
+		}
+	}
+	telemetry.FreteRapidoCircuitState.Set(float64(CircuitClosed))
 	return &FreteRapidoAdapter{
-		client: &http.Client{},
-		repo:   repo,
+		client:  &http.Client{Timeout: cfg.Timeout},
+		retry:   cfg.Retry,
+		breaker: breaker,
+		logger:  cfg.Logger,
+	}
+}
+
+func (fra *FreteRapidoAdapter) BreakerStats() CircuitBreakerStats {
+	return fra.breaker.Stats()
+}
+
+// HealthCheck usa o resultado das últimas chamadas em vez de sondar a Frete
+// Rápido, que cobra e limita as requisições de cotação: falha com o circuito
+// aberto ou quando a chamada mais recente não teve sucesso.
+func (fra *FreteRapidoAdapter) HealthCheck(ctx context.Context) error {
+	stats := fra.breaker.Stats()
+	if stats.State == CircuitOpen {
+		return fmt.Errorf("circuit breaker %s aberto após %d falhas consecutivas", freteRapidoProvider, stats.ConsecutiveFailures)
 	}
+	if stats.ConsecutiveFailures > 0 {
+		return fmt.Errorf("a última chamada falhou (%d falhas consecutivas)", stats.ConsecutiveFailures)
+	}
+	return nil
 }
 
-func (fra *FreteRapidoAdapter) Execute(quoteData quote.QuoteRequest) ([]quote.Offer, error) {
+func (fra *FreteRapidoAdapter) Execute(ctx context.Context, quoteData quote.QuoteRequest) (offers []quote.Offer, err error) {
+	ctx, span := telemetry.StartSpan(ctx, "FreteRapidoAdapter.Execute")
+	defer func() {
+		span.SetAttributes(attribute.Int("quote.offers", len(offers)))
+		telemetry.RecordError(span, err)
+		span.End()
+	}()
+
 	freteApiRequest := http2.DomainToFreteRapidoContractRequest(quoteData)
 	requestPayload, err := json.Marshal(freteApiRequest)
 	if err != nil {
 		return nil, err
 	}
 
-	response, err := fra.client.Post("https://sp.freterapido.com/api/v3/quote/simulate", "application/json", bytes.NewBuffer(requestPayload))
-	if err != nil {
-		return nil, err
+	if err := fra.breaker.Allow(); err != nil {
+		return nil, &quote.UpstreamUnavailableError{Provider: freteRapidoProvider, Err: err}
 	}
-	if response.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(response.Body)
-		return nil, fmt.Errorf("frete Rapido Contract returned %s", string(body))
+
+	var body []byte
+	for attempt := 0; ; attempt++ {
+		body, err = fra.post(ctx, requestPayload, quoteData.Shipper.Token)
+		if err == nil {
+			fra.breaker.Success()
+			break
+		}
+		if ctx.Err() != nil {
+			fra.breaker.Cancel()
+			return nil, ctx.Err()
+		}
+		if !isRetryable(err) {
+			// recusa de contrato: o upstream está respondendo normalmente
+			fra.breaker.Success()
+			return nil, classifyUpstreamError(err)
+		}
+		delay := fra.retryDelay(attempt, err)
+		if attempt >= fra.retry.MaxRetries || !fitsDeadline(ctx, delay) {
+			fra.breaker.Failure()
+			return nil, classifyUpstreamError(err)
+		}
+		fra.logger.WarnContext(ctx, "tentativa na frete rápido falhou, repetindo",
+			slog.Int("attempt", attempt+1),
+			slog.Duration("retry_in", delay),
+			slog.String("error", err.Error()),
+		)
+		select {
+		case <-ctx.Done():
+			fra.breaker.Cancel()
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
 	}
-	defer response.Body.Close()
 
 	var freteApiResponse http2.FreteRapidoApiResponse
-	if err := json.NewDecoder(response.Body).Decode(&freteApiResponse); err != nil {
+	if err := json.Unmarshal(body, &freteApiResponse); err != nil {
+		return nil, &quote.UpstreamUnavailableError{Provider: freteRapidoProvider, Err: err}
+	}
+	return http2.FreteApiResponseToDomainOffer(quoteData, freteApiResponse), nil
+}
+
+// post devolve o corpo das respostas de erro com o token trocado por
+// logging.Redacted: ele vai para logs e traces e a Frete Rápido pode ecoar o
+// que recebeu.
+func (fra *FreteRapidoAdapter) post(ctx context.Context, payload []byte, token string) (body []byte, err error) {
+	ctx, span := telemetry.StartSpan(ctx, "POST frete_rapido", trace.WithSpanKind(trace.SpanKindClient))
+	defer func() {
+		telemetry.RecordError(span, err)
+		span.End()
+	}()
+	span.SetAttributes(semconv.HTTPRequestMethodPost, semconv.URLFull(freteRapidoSimulateURL))
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, freteRapidoSimulateURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(request.Header))
+
+	start := time.Now()
+	response, err := fra.client.Do(request)
+	telemetry.FreteRapidoDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		telemetry.FreteRapidoRequests.WithLabelValues("error").Inc()
 		return nil, err
 	}
-	offers := http2.FreteApiResponseToDomainOffer(freteApiResponse)
-	if err = fra.repo.SaveAllOffers(offers); err != nil {
+	telemetry.FreteRapidoRequests.WithLabelValues(strconv.Itoa(response.StatusCode)).Inc()
+	fra.logger.DebugContext(ctx, "resposta da frete rápido",
+		slog.Int("status", response.StatusCode),
+		slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+	)
+	span.SetAttributes(semconv.HTTPResponseStatusCode(response.StatusCode))
+	defer response.Body.Close()
+	body, err = io.ReadAll(response.Body)
+	if err != nil {
 		return nil, err
 	}
+	if response.StatusCode != http.StatusOK {
+		return nil, &UpstreamStatusError{
+			StatusCode: response.StatusCode,
+			Body:       redactToken(string(body), token),
+			RetryAfter: parseRetryAfter(response.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+	return body, nil
+}
+
+func redactToken(body, token string) string {
+	if token == "" {
+		return body
+	}
+	return strings.ReplaceAll(body, token, logging.Redacted)
+}
 
-	return offers, nil
+// retryDelay usa o backoff da política, ou o Retry-After pedido pelo upstream
+// quando ele for maior.
+func (fra *FreteRapidoAdapter) retryDelay(attempt int, err error) time.Duration {
+	delay := fra.retry.Backoff(attempt)
+	var statusErr *UpstreamStatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > delay {
+		delay = statusErr.RetryAfter
+	}
+	return delay
+}
+
+// fitsDeadline diz se ainda vale esperar delay antes da próxima tentativa:
+// se o prazo da requisição vence antes, a falha é devolvida já.
+func fitsDeadline(ctx context.Context, delay time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Until(deadline) > delay
+}
+
+// parseRetryAfter aceita os dois formatos do cabeçalho: segundos ou data HTTP.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// retryableStatus são as respostas em que repetir pode dar outro resultado:
+// 5xx, timeout (408) e limite de requisições (429). Os demais 4xx são de
+// contrato.
+func retryableStatus(code int) bool {
+	return code >= http.StatusInternalServerError || code == http.StatusRequestTimeout || code == http.StatusTooManyRequests
+}
+
+// isRetryable considera falhas de rede, timeouts e os status de
+// retryableStatus.
+func isRetryable(err error) bool {
+	var statusErr *UpstreamStatusError
+	if errors.As(err, &statusErr) {
+		return retryableStatus(statusErr.StatusCode)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// classifyUpstreamError converte a falha na chamada em erro de domínio: 4xx de
+// contrato é recusa, o resto (inclusive 408 e 429) é indisponibilidade do
+// upstream.
+func classifyUpstreamError(err error) error {
+	var statusErr *UpstreamStatusError
+	if errors.As(err, &statusErr) && !retryableStatus(statusErr.StatusCode) {
+		return &quote.UpstreamRejectedError{Provider: freteRapidoProvider, Err: err}
+	}
+	return &quote.UpstreamUnavailableError{Provider: freteRapidoProvider, Err: err}
 }
@@ -0,0 +1,121 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+
+	// StatusOK indica todas as dependências no ar; StatusDegraded, que só
+	// dependências não críticas falharam; StatusUnavailable, que alguma
+	// dependência crítica falhou e o serviço não deve receber tráfego.
+	StatusOK          = "ok"
+	StatusDegraded    = "degraded"
+	StatusUnavailable = "unavailable"
+
+	DefaultCheckTimeout = 2 * time.Second
+)
+
+// Check verifica uma dependência. Uma dependência crítica fora do ar tira o
+// serviço de prontidão; as demais só o marcam como degradado.
+type Check struct {
+	Name     string
+	Critical bool
+	Probe    func(ctx context.Context) error
+}
+
+type DependencyStatus struct {
+	Name     string
+	Status   string
+	Critical bool
+	Latency  time.Duration
+	Error    string
+}
+
+type Report struct {
+	Status       string
+	Dependencies []DependencyStatus
+}
+
+// Checker roda todas as verificações em paralelo, cada uma limitada a timeout.
+type Checker struct {
+	checks  []Check
+	timeout time.Duration
+}
+
+func NewChecker(timeout time.Duration, checks ...Check) *Checker {
+	if timeout <= 0 {
+		timeout = DefaultCheckTimeout
+	}
+	return &Checker{checks: checks, timeout: timeout}
+}
+
+func (c *Checker) Check(ctx context.Context) Report {
+	dependencies := make([]DependencyStatus, len(c.checks))
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			dependencies[i] = c.run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Dependencies: dependencies}
+	for _, dependency := range dependencies {
+		if dependency.Status == StatusUp {
+			continue
+		}
+		if dependency.Critical {
+			report.Status = StatusUnavailable
+			break
+		}
+		report.Status = StatusDegraded
+	}
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := probe(ctx, check.Probe)
+	status := DependencyStatus{
+		Name:     check.Name,
+		Status:   StatusUp,
+		Critical: check.Critical,
+		Latency:  time.Since(start),
+	}
+	if err != nil {
+		status.Status = StatusDown
+		status.Error = err.Error()
+	}
+	return status
+}
+
+// probe devolve o erro do contexto se a verificação não respeitar o prazo,
+// para que uma dependência travada não segure o /readyz.
+func probe(ctx context.Context, fn func(ctx context.Context) error) error {
+	result := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				result <- fmt.Errorf("verificação falhou: %v", r)
+			}
+		}()
+		result <- fn(ctx)
+	}()
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
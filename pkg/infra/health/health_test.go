@@ -0,0 +1,60 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func up(ctx context.Context) error { return nil }
+
+func down(ctx context.Context) error { return errors.New("connection refused") }
+
+func TestCheckerReportsEachDependency(t *testing.T) {
+	checker := NewChecker(time.Second,
+		Check{Name: "postgres", Critical: true, Probe: up},
+		Check{Name: "cache", Probe: down},
+	)
+
+	report := checker.Check(context.Background())
+
+	assert.Equal(t, StatusDegraded, report.Status)
+	assert.Len(t, report.Dependencies, 2)
+	assert.Equal(t, "postgres", report.Dependencies[0].Name)
+	assert.Equal(t, StatusUp, report.Dependencies[0].Status)
+	assert.Equal(t, "cache", report.Dependencies[1].Name)
+	assert.Equal(t, StatusDown, report.Dependencies[1].Status)
+	assert.Equal(t, "connection refused", report.Dependencies[1].Error)
+}
+
+func TestCheckerUnavailableWhenCriticalDependencyFails(t *testing.T) {
+	checker := NewChecker(time.Second,
+		Check{Name: "cache", Probe: down},
+		Check{Name: "postgres", Critical: true, Probe: down},
+	)
+
+	assert.Equal(t, StatusUnavailable, checker.Check(context.Background()).Status)
+}
+
+func TestCheckerAllUp(t *testing.T) {
+	checker := NewChecker(time.Second, Check{Name: "postgres", Critical: true, Probe: up})
+
+	assert.Equal(t, StatusOK, checker.Check(context.Background()).Status)
+}
+
+func TestCheckerTimesOutStuckProbe(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	checker := NewChecker(20*time.Millisecond, Check{Name: "postgres", Critical: true, Probe: func(ctx context.Context) error {
+		<-release
+		return nil
+	}})
+
+	report := checker.Check(context.Background())
+
+	assert.Equal(t, StatusUnavailable, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Dependencies[0].Error)
+	assert.Less(t, report.Dependencies[0].Latency, time.Second)
+}
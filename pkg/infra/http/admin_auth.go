@@ -0,0 +1,30 @@
+package http
+
+import (
+	"crypto/subtle"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+// AdminAuthMiddleware exige o token administrativo em
+// "Authorization: Bearer <token>" nas rotas de operação, como /admin/cache e
+// /internal/metrics. Sem token configurado as rotas ficam fechadas para
+// todos, em vez de abertas.
+func AdminAuthMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			JSONErrorResponse(http.StatusForbidden, ErrCodeForbidden, "rotas administrativas desabilitadas: configure ADMIN_TOKEN", c)
+			c.Abort()
+			return
+		}
+		received, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(received), []byte(token)) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="admin"`)
+			JSONErrorResponse(http.StatusUnauthorized, ErrCodeUnauthorized, "token administrativo ausente ou inválido", c)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
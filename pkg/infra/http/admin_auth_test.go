@@ -0,0 +1,47 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func adminRouter(token string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/admin/cache/stats", AdminAuthMiddleware(token), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		expected      int
+	}{
+		{"token correto", "admin-token-123456", "Bearer admin-token-123456", http.StatusOK},
+		{"sem cabeçalho", "admin-token-123456", "", http.StatusUnauthorized},
+		{"token errado", "admin-token-123456", "Bearer outro-token", http.StatusUnauthorized},
+		{"sem Bearer", "admin-token-123456", "admin-token-123456", http.StatusUnauthorized},
+		{"sem token configurado", "", "Bearer ", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin/cache/stats", nil)
+			req.Header.Set("Authorization", tt.authorization)
+			rec := httptest.NewRecorder()
+			adminRouter(tt.token).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expected, rec.Code)
+			if tt.expected == http.StatusUnauthorized {
+				assert.Contains(t, rec.Header().Get("WWW-Authenticate"), "Bearer")
+				assert.Contains(t, rec.Body.String(), ErrCodeUnauthorized)
+			}
+		})
+	}
+}
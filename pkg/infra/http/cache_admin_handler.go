@@ -0,0 +1,116 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/cache"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/logging"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// QuoteCacheAdmin são as operações administrativas sobre o cache de cotações.
+type QuoteCacheAdmin interface {
+	Stats() cache.Stats
+	Inspect(ctx context.Context, key string) (string, error)
+	PurgeZipcode(ctx context.Context, zipcode int) (int, error)
+	PurgeSKU(ctx context.Context, sku string) (int, error)
+	Flush(ctx context.Context) (int, error)
+}
+
+type CacheStatsResponse struct {
+	Hits              int64   `json:"hits"`
+	StaleHits         int64   `json:"stale_hits"`
+	Misses            int64   `json:"misses"`
+	Errors            int64   `json:"errors"`
+	HitRate           float64 `json:"hit_rate"`
+	AvgSavedLatencyMs float64 `json:"avg_saved_latency_ms"`
+}
+
+type CacheEntryResponse struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+type CachePurgeResponse struct {
+	Deleted int `json:"deleted"`
+}
+
+type CacheAdminHandler struct {
+	admin  QuoteCacheAdmin
+	logger *slog.Logger
+}
+
+func NewCacheAdminHandler(admin QuoteCacheAdmin, logger *slog.Logger) *CacheAdminHandler {
+	return &CacheAdminHandler{admin: admin, logger: logging.OrDefault(logger)}
+}
+
+func (h *CacheAdminHandler) GetStats(c *gin.Context) {
+	stats := h.admin.Stats()
+	c.JSON(http.StatusOK, CacheStatsResponse{
+		Hits:              stats.Hits,
+		StaleHits:         stats.StaleHits,
+		Misses:            stats.Misses,
+		Errors:            stats.Errors,
+		HitRate:           stats.HitRate(),
+		AvgSavedLatencyMs: float64(stats.AvgSavedLatency.Microseconds()) / 1000,
+	})
+}
+
+func (h *CacheAdminHandler) GetEntry(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	value, err := h.admin.Inspect(c.Request.Context(), key)
+	if errors.Is(err, cache.ErrCacheMiss) {
+		JSONErrorResponse(http.StatusNotFound, ErrCodeNotFound, "chave não encontrada no cache", c)
+		return
+	}
+	if err != nil {
+		h.cacheErrorResponse(err, c)
+		return
+	}
+	c.JSON(http.StatusOK, CacheEntryResponse{Key: key, Value: json.RawMessage(value)})
+}
+
+func (h *CacheAdminHandler) PurgeZipcode(c *gin.Context) {
+	zipcode, err := ConverterStrinToInZipcode(strings.ReplaceAll(c.Param("zipcode"), "-", ""))
+	if err != nil {
+		JSONFieldErrorResponse(http.StatusBadRequest, ErrCodeInvalidRequest, "zipcode", err.Error(), c)
+		return
+	}
+	deleted, err := h.admin.PurgeZipcode(c.Request.Context(), zipcode)
+	if err != nil {
+		h.cacheErrorResponse(err, c)
+		return
+	}
+	c.JSON(http.StatusOK, CachePurgeResponse{Deleted: deleted})
+}
+
+func (h *CacheAdminHandler) PurgeSKU(c *gin.Context) {
+	deleted, err := h.admin.PurgeSKU(c.Request.Context(), c.Param("sku"))
+	if err != nil {
+		h.cacheErrorResponse(err, c)
+		return
+	}
+	c.JSON(http.StatusOK, CachePurgeResponse{Deleted: deleted})
+}
+
+func (h *CacheAdminHandler) Flush(c *gin.Context) {
+	deleted, err := h.admin.Flush(c.Request.Context())
+	if err != nil {
+		h.cacheErrorResponse(err, c)
+		return
+	}
+	c.JSON(http.StatusOK, CachePurgeResponse{Deleted: deleted})
+}
+
+func (h *CacheAdminHandler) cacheErrorResponse(err error, c *gin.Context) {
+	h.logger.Log(c.Request.Context(), errorLogLevel(err), "erro ao acessar o cache", slog.String("error", err.Error()))
+	if errors.Is(err, context.Canceled) {
+		JSONDomainErrorResponse(err, c)
+		return
+	}
+	JSONErrorResponse(http.StatusServiceUnavailable, ErrCodeCacheUnavailable, "o cache está indisponível no momento", c)
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/cache"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fakeCacheAdmin struct {
+	entries map[string]string
+	purged  int
+	err     error
+}
+
+func (f *fakeCacheAdmin) Stats() cache.Stats {
+	return cache.Stats{Hits: 3, Misses: 1, AvgSavedLatency: 1500 * time.Microsecond}
+}
+
+func (f *fakeCacheAdmin) Inspect(ctx context.Context, key string) (string, error) {
+	value, ok := f.entries[key]
+	if !ok {
+		return "", cache.ErrCacheMiss
+	}
+	return value, nil
+}
+
+func (f *fakeCacheAdmin) PurgeZipcode(ctx context.Context, zipcode int) (int, error) {
+	return f.purged, f.err
+}
+
+func (f *fakeCacheAdmin) PurgeSKU(ctx context.Context, sku string) (int, error) {
+	return f.purged, f.err
+}
+
+func (f *fakeCacheAdmin) Flush(ctx context.Context) (int, error) {
+	return f.purged, f.err
+}
+
+func cacheAdminRouter(admin QuoteCacheAdmin) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := NewCacheAdminHandler(admin, nil)
+	r := gin.New()
+	r.GET("/admin/cache/stats", handler.GetStats)
+	r.GET("/admin/cache/entries/*key", handler.GetEntry)
+	r.DELETE("/admin/cache/zipcodes/:zipcode", handler.PurgeZipcode)
+	return r
+}
+
+func TestCacheAdminHandler(t *testing.T) {
+	admin := &fakeCacheAdmin{
+		entries: map[string]string{"quote:v2:01311000:abc": `{"offers":[]}`},
+		purged:  4,
+	}
+	r := cacheAdminRouter(admin)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		statusCode int
+		body       string
+	}{
+		{"Estatísticas", http.MethodGet, "/admin/cache/stats", http.StatusOK, `"hit_rate":0.75`},
+		{"Entrada existente", http.MethodGet, "/admin/cache/entries/quote:v2:01311000:abc", http.StatusOK, `"value":{"offers":[]}`},
+		{"Entrada inexistente", http.MethodGet, "/admin/cache/entries/quote:v2:0:nada", http.StatusNotFound, ErrCodeNotFound},
+		{"Expurgo por CEP", http.MethodDelete, "/admin/cache/zipcodes/01311-000", http.StatusOK, `"deleted":4`},
+		{"CEP inválido", http.MethodDelete, "/admin/cache/zipcodes/abc", http.StatusBadRequest, ErrCodeInvalidRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			assert.Equal(t, tt.statusCode, w.Code)
+			assert.Contains(t, w.Body.String(), tt.body)
+		})
+	}
+}
+
+func TestCacheAdminHandlerCacheUnavailable(t *testing.T) {
+	r := cacheAdminRouter(&fakeCacheAdmin{err: errors.New("redis fora do ar")})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/cache/zipcodes/01311000", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), ErrCodeCacheUnavailable)
+}
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/logging"
+	"log/slog"
+	"net/http"
+)
+
+// StatusClientClosedRequest é o 499 do nginx: o cliente desistiu antes da
+// resposta.
+const StatusClientClosedRequest = 499
+
+const (
+	ErrCodeInvalidRequest      = "invalid_request"
+	ErrCodeValidationFailed    = "validation_failed"
+	ErrCodeUpstreamRejected    = "upstream_rejected"
+	ErrCodeUpstreamUnavailable = "upstream_unavailable"
+	ErrCodePersistenceFailed   = "persistence_failed"
+	ErrCodeNotFound            = "not_found"
+	ErrCodeRequestCanceled     = "request_canceled"
+	ErrCodeUnauthorized        = "unauthorized"
+	ErrCodeForbidden           = "forbidden"
+	ErrCodeCacheUnavailable    = "cache_unavailable"
+	ErrCodeInternal            = "internal_error"
+)
+
+type FieldViolation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type ErrorResponse struct {
+	Code       string           `json:"code"`
+	Message    string           `json:"message"`
+	Field      string           `json:"field,omitempty"`
+	Violations []FieldViolation `json:"violations,omitempty"`
+}
+
+// DomainErrorToResponse traduz os erros tipados do pacote quote para o status
+// HTTP e o código estável devolvido ao cliente.
+func DomainErrorToResponse(err error) (int, ErrorResponse) {
+	var validationErrs quote.ValidationErrors
+	var validationErr *quote.ValidationError
+	var rejectedErr *quote.UpstreamRejectedError
+	var unavailableErr *quote.UpstreamUnavailableError
+	var persistenceErr *quote.PersistenceError
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return StatusClientClosedRequest, ErrorResponse{
+			Code:    ErrCodeRequestCanceled,
+			Message: "a requisição foi cancelada pelo cliente",
+		}
+	case errors.Is(err, quote.ErrQuoteNotFound):
+		return http.StatusNotFound, ErrorResponse{
+			Code:    ErrCodeNotFound,
+			Message: err.Error(),
+		}
+	case errors.As(err, &validationErrs):
+		violations := make([]FieldViolation, len(validationErrs))
+		for i, v := range validationErrs {
+			violations[i] = FieldViolation{Field: v.Field, Message: v.Message}
+		}
+		return http.StatusUnprocessableEntity, ErrorResponse{
+			Code:       ErrCodeValidationFailed,
+			Message:    "a cotação possui campos inválidos",
+			Violations: violations,
+		}
+	case errors.As(err, &validationErr):
+		return http.StatusUnprocessableEntity, ErrorResponse{
+			Code:    ErrCodeValidationFailed,
+			Message: validationErr.Message,
+			Field:   validationErr.Field,
+		}
+	case errors.As(err, &rejectedErr):
+		return http.StatusBadGateway, ErrorResponse{
+			Code:    ErrCodeUpstreamRejected,
+			Message: "o provedor de frete recusou a cotação",
+		}
+	case errors.As(err, &unavailableErr), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable, ErrorResponse{
+			Code:    ErrCodeUpstreamUnavailable,
+			Message: "o provedor de frete está indisponível no momento",
+		}
+	case errors.As(err, &persistenceErr):
+		return http.StatusInternalServerError, ErrorResponse{
+			Code:    ErrCodePersistenceFailed,
+			Message: "não foi possível salvar a cotação",
+		}
+	default:
+		return http.StatusInternalServerError, ErrorResponse{
+			Code:    ErrCodeInternal,
+			Message: "erro interno ao processar a requisição",
+		}
+	}
+}
+
+// errorLogLevel é o nível do log de err: cancelamentos pelo cliente não são
+// falha do serviço e não devem aparecer como erro.
+func errorLogLevel(err error) slog.Level {
+	if errors.Is(err, context.Canceled) {
+		return slog.LevelInfo
+	}
+	return slog.LevelError
+}
+
+func JSONDomainErrorResponse(err error, c *gin.Context) {
+	statusCode, response := DomainErrorToResponse(err)
+	c.JSON(statusCode, redactErrorResponse(response))
+}
+
+func JSONErrorResponse(statusCode int, code string, message string, c *gin.Context) {
+	c.JSON(statusCode, redactErrorResponse(ErrorResponse{Code: code, Message: message}))
+}
+
+// JSONFieldErrorResponse é o JSONErrorResponse apontando o campo inválido.
+func JSONFieldErrorResponse(statusCode int, code string, field string, message string, c *gin.Context) {
+	c.JSON(statusCode, redactErrorResponse(ErrorResponse{Code: code, Message: message, Field: field}))
+}
+
+// redactErrorResponse garante que nenhum segredo (como o token da Frete
+// Rápido) volte ao cliente dentro de uma mensagem de erro.
+func redactErrorResponse(response ErrorResponse) ErrorResponse {
+	response.Message = logging.Redact(response.Message)
+	for i := range response.Violations {
+		response.Violations[i].Message = logging.Redact(response.Violations[i].Message)
+	}
+	return response
+}
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/logging"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDomainErrorToResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		statusCode int
+		code       string
+	}{
+		{
+			name:       "Erro de validação",
+			err:        &quote.ValidationError{Field: "recipient.zipcode", Message: "CEP inválido"},
+			statusCode: http.StatusUnprocessableEntity,
+			code:       ErrCodeValidationFailed,
+		},
+		{
+			name:       "Cotação não encontrada",
+			err:        quote.ErrQuoteNotFound,
+			statusCode: http.StatusNotFound,
+			code:       ErrCodeNotFound,
+		},
+		{
+			name:       "Upstream recusou",
+			err:        &quote.UpstreamRejectedError{Provider: "frete_rapido", Err: errors.New("400")},
+			statusCode: http.StatusBadGateway,
+			code:       ErrCodeUpstreamRejected,
+		},
+		{
+			name:       "Upstream indisponível encapsulado",
+			err:        fmt.Errorf("provedor frete_rapido: %w", &quote.UpstreamUnavailableError{Err: errors.New("timeout")}),
+			statusCode: http.StatusServiceUnavailable,
+			code:       ErrCodeUpstreamUnavailable,
+		},
+		{
+			name:       "Deadline da requisição",
+			err:        context.DeadlineExceeded,
+			statusCode: http.StatusServiceUnavailable,
+			code:       ErrCodeUpstreamUnavailable,
+		},
+		{
+			name:       "Cliente desistiu",
+			err:        fmt.Errorf("provedor frete_rapido: %w", context.Canceled),
+			statusCode: StatusClientClosedRequest,
+			code:       ErrCodeRequestCanceled,
+		},
+		{
+			name:       "Falha de persistência",
+			err:        &quote.PersistenceError{Err: errors.New("conn refused")},
+			statusCode: http.StatusInternalServerError,
+			code:       ErrCodePersistenceFailed,
+		},
+		{
+			name:       "Erro desconhecido",
+			err:        errors.New("boom"),
+			statusCode: http.StatusInternalServerError,
+			code:       ErrCodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statusCode, response := DomainErrorToResponse(tt.err)
+			assert.Equal(t, tt.statusCode, statusCode)
+			assert.Equal(t, tt.code, response.Code)
+		})
+	}
+}
+
+func TestDomainErrorToResponseListsAllViolations(t *testing.T) {
+	var errs quote.ValidationErrors
+	errs.Add("dispatchers[0].volumes[0].height", "altura deve ser maior que zero")
+	errs.Add("dispatchers[0].volumes[3].height", "altura deve ser maior que zero")
+
+	statusCode, response := DomainErrorToResponse(errs)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, statusCode)
+	assert.Equal(t, ErrCodeValidationFailed, response.Code)
+	assert.Equal(t, []FieldViolation{
+		{Field: "dispatchers[0].volumes[0].height", Message: "altura deve ser maior que zero"},
+		{Field: "dispatchers[0].volumes[3].height", Message: "altura deve ser maior que zero"},
+	}, response.Violations)
+}
+
+func TestJSONErrorResponseRedactsSecrets(t *testing.T) {
+	const token = "9f2c4e6a8b0d1f3e5a7c9e1b3d5f7a9c"
+	logging.RegisterSecret(token)
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	JSONDomainErrorResponse(quote.ValidationErrors{{Field: "shipper.token", Message: "token " + token + " inválido"}}, c)
+
+	assert.NotContains(t, rec.Body.String(), token)
+	assert.Contains(t, rec.Body.String(), logging.Redacted)
+
+	rec = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(rec)
+	JSONErrorResponse(http.StatusBadRequest, ErrCodeInvalidRequest, "json inválido perto de "+token, c)
+
+	assert.NotContains(t, rec.Body.String(), token)
+}
+
+func TestErrorLogLevel(t *testing.T) {
+	assert.Equal(t, slog.LevelInfo, errorLogLevel(fmt.Errorf("simulação: %w", context.Canceled)))
+	assert.Equal(t, slog.LevelError, errorLogLevel(context.DeadlineExceeded))
+	assert.Equal(t, slog.LevelError, errorLogLevel(errors.New("boom")))
+}
@@ -20,9 +20,10 @@ type Shipper struct {
 }
 
 type Recipient struct {
-	Type    int    `json:"type"`
-	Zipcode int    `json:"zipcode"`
-	Country string `json:"country"`
+	Type             int    `json:"type"`
+	RegisteredNumber string `json:"registered_number,omitempty"`
+	Zipcode          int    `json:"zipcode"`
+	Country          string `json:"country"`
 }
 
 type Dispatcher struct {
@@ -31,7 +32,9 @@ type Dispatcher struct {
 	Volumes          []VolumeApiRequest `json:"volumes"`
 }
 type DispatcherResponse struct {
-	Offer []OfferResponse `json:"offers"`
+	RegisteredNumberDispatcher string          `json:"registered_number_dispatcher"`
+	ZipcodeOrigin              int             `json:"zipcode_origin"`
+	Offer                      []OfferResponse `json:"offers"`
 }
 
 type OfferResponse struct {
@@ -95,9 +98,10 @@ func DomainToFreteRapidoContractRequest(request quote.QuoteRequest) FreteRapidoA
 			PlatformCode:     request.Shipper.PlatformCode,
 		},
 		Recipient: Recipient{
-			Type:    request.Recipient.Type,
-			Country: request.Recipient.Country,
-			Zipcode: request.Recipient.Zipcode,
+			Type:             request.Recipient.Type,
+			RegisteredNumber: request.Recipient.RegisteredNumber,
+			Country:          request.Recipient.Country,
+			Zipcode:          request.Recipient.Zipcode,
 		},
 		Dispatchers: func() []Dispatcher {
 			var dispatchers []Dispatcher
@@ -111,14 +115,30 @@ func DomainToFreteRapidoContractRequest(request quote.QuoteRequest) FreteRapidoA
 	}
 }
 
-func FreteApiResponseToDomainOffer(response FreteRapidoApiResponse) []quote.Offer {
+// responseWarehouseID descobre de qual expedidor da requisição veio o bloco
+// de ofertas: pelo CEP de origem e CNPJ e, se a API não os devolver, pela posição.
+func responseWarehouseID(request quote.QuoteRequest, dispatcher DispatcherResponse, position int) string {
+	for _, d := range request.Dispatchers {
+		if d.Zipcode == dispatcher.ZipcodeOrigin && d.RegisteredNumber == dispatcher.RegisteredNumberDispatcher {
+			return d.WarehouseID
+		}
+	}
+	if position < len(request.Dispatchers) {
+		return request.Dispatchers[position].WarehouseID
+	}
+	return ""
+}
+
+func FreteApiResponseToDomainOffer(request quote.QuoteRequest, response FreteRapidoApiResponse) []quote.Offer {
 	var offers []quote.Offer
-	for _, d := range response.Dispatchers {
+	for i, d := range response.Dispatchers {
+		warehouseID := responseWarehouseID(request, d, i)
 		for _, offer := range d.Offer {
 			offerDomain := quote.Offer{
-				Carrier:    offer.Carrier.Name,
-				Service:    offer.Service,
-				FinalPrice: offer.FinalPrice,
+				WarehouseID: warehouseID,
+				Carrier:     offer.Carrier.Name,
+				Service:     offer.Service,
+				FinalPrice:  offer.FinalPrice,
 				DeliveryTime: func() int {
 					if offer.DeliveryTime.Days == 0 {
 						return 1
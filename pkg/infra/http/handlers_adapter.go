@@ -2,105 +2,173 @@ package http
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
-	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/cache"
-	"github.com/redis/go-redis/v9"
-	"log"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/logging"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"log/slog"
 	"net/http"
-	"sort"
-	"strconv"
-	"strings"
-	"time"
 )
 
 type QuoteAdapterHandler struct {
-	inputSimulate quote.SimulateInputPort
-	inputMetrics  quote.MetricsInputPort
-	redisCache    cache.IRedisCache
+	inputSimulate  quote.SimulateInputPort
+	inputMetrics   quote.MetricsInputPort
+	inputWarehouse quote.WarehouseInputPort
+	inputHistory   quote.QuoteHistoryInputPort
+	shipper        quote.Shipper
+	logger         *slog.Logger
 }
 
-func NewQuoteAdapterHandler(inputSimulate quote.SimulateInputPort, inputMetrics quote.MetricsInputPort, redis cache.IRedisCache) *QuoteAdapterHandler {
+func NewQuoteAdapterHandler(inputSimulate quote.SimulateInputPort, inputMetrics quote.MetricsInputPort, inputWarehouse quote.WarehouseInputPort, inputHistory quote.QuoteHistoryInputPort, shipper quote.Shipper, logger *slog.Logger) *QuoteAdapterHandler {
 	return &QuoteAdapterHandler{
-		inputSimulate: inputSimulate,
-		inputMetrics:  inputMetrics,
-		redisCache:    redis,
+		inputSimulate:  inputSimulate,
+		inputMetrics:   inputMetrics,
+		inputWarehouse: inputWarehouse,
+		inputHistory:   inputHistory,
+		shipper:        shipper,
+		logger:         logging.OrDefault(logger),
 	}
 }
 
 func (q *QuoteAdapterHandler) SimulateQuote(c *gin.Context) {
+	ctx, span := telemetry.StartSpan(c.Request.Context(), "SimulateQuote")
+	defer span.End()
+
 	var simulateRequest SimulateQuoteRequest
-	var offersResponse SimulateQuoteResponse
 	if err := c.ShouldBindJSON(&simulateRequest); err != nil {
-		JSONErrorResponse(http.StatusBadRequest, "Error ao converter json em struct", err, c)
+		JSONErrorResponse(http.StatusBadRequest, ErrCodeInvalidRequest, err.Error(), c)
 		return
 	}
 
 	zipcode, err := ConverterStrinToInZipcode(simulateRequest.Recipient.Address.Zipcode)
 	if err != nil {
-		JSONErrorResponse(http.StatusBadRequest, "Error ao converter json em struct", err, c)
+		JSONFieldErrorResponse(http.StatusBadRequest, ErrCodeInvalidRequest, "recipient.address.zipcode", err.Error(), c)
 		return
 	}
-	var skuAmounts []string
-	for _, v := range simulateRequest.Volumes {
-		skuAmounts = append(skuAmounts, fmt.Sprintf("%s-%d", v.Sku, v.Amount))
+	logging.AddAttrs(ctx, slog.Int("zipcode", zipcode))
+	origin, origins, err := q.resolveOrigins(ctx, simulateRequest, zipcode)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		JSONDomainErrorResponse(err, c)
+		return
+	}
+
+	_, convertSpan := telemetry.StartSpan(ctx, "RequestToDomainQuote")
+	quoteRequest, err := RequestToDomainQuote(simulateRequest, q.shipper, *origin, origins)
+	telemetry.RecordError(convertSpan, err)
+	convertSpan.End()
+	if err != nil {
+		telemetry.RecordError(span, err)
+		JSONDomainErrorResponse(err, c)
+		return
 	}
-	sort.Strings(skuAmounts)
-	cachedKey := fmt.Sprintf("%d-%s", zipcode, strings.Join(skuAmounts, "-"))
-	ctx := context.Background()
-	resultCached, err := q.redisCache.Get(ctx, cachedKey)
-	if err == redis.Nil {
-		log.Println("Cache não encontrado para a key:", cachedKey)
+
+	simulated, err := q.inputSimulate.Simulate(ctx, *quoteRequest)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		q.logger.Log(ctx, errorLogLevel(err), "erro ao simular cotações", slog.String("error", err.Error()))
+		JSONDomainErrorResponse(err, c)
+		return
 	}
 
-	if resultCached != "" {
-		err = json.Unmarshal([]byte(resultCached), &offersResponse)
+	telemetry.OffersPerQuote.Observe(float64(len(simulated.Offers)))
+	offersResponse := DomainToSimulateQuoteResponse(simulated.Request.Dispatchers, simulated.Offers)
+	offersResponse.QuoteID = simulated.ID
+	span.SetAttributes(attribute.String("quote.id", simulated.ID), attribute.Int("quote.offers", len(simulated.Offers)))
+
+	c.JSON(http.StatusOK, offersResponse)
+	return
+}
+
+// resolveOrigins resolve a origem padrão da requisição e cada centro de
+// distribuição indicado nos volumes.
+func (q *QuoteAdapterHandler) resolveOrigins(ctx context.Context, request SimulateQuoteRequest, zipcode int) (*quote.Warehouse, map[string]quote.Warehouse, error) {
+	origin, err := q.inputWarehouse.ResolveOrigin(ctx, request.Origin.WarehouseID, zipcode)
+	if err != nil {
+		return nil, nil, err
+	}
+	origins := map[string]quote.Warehouse{origin.ID: *origin}
+	for i, v := range request.Volumes {
+		if v.WarehouseID == "" {
+			continue
+		}
+		if _, ok := origins[v.WarehouseID]; ok {
+			continue
+		}
+		warehouse, err := q.inputWarehouse.ResolveOrigin(ctx, v.WarehouseID, zipcode)
 		if err != nil {
-			log.Println("Não foi possivel converter o cache me json. Error: ", err.Error())
-		} else {
-			log.Println("Resultado retornado em cache")
-			c.JSON(http.StatusOK, offersResponse)
-			return
+			var validationErr *quote.ValidationError
+			if errors.As(err, &validationErr) {
+				return nil, nil, &quote.ValidationError{Field: fmt.Sprintf("volumes[%d].warehouse_id", i), Message: validationErr.Message}
+			}
+			return nil, nil, err
 		}
+		origins[warehouse.ID] = *warehouse
 	}
+	return origin, origins, nil
+}
 
-	quoteRequest, err := RequestToDomainQuote(simulateRequest)
+func (q *QuoteAdapterHandler) GetMetrics(c *gin.Context) {
+	filter, err := QueryToMetricsFilter(c)
 	if err != nil {
-		JSONErrorResponse(http.StatusBadRequest, "Error processar dados", err, c)
+		JSONDomainErrorResponse(err, c)
 		return
 	}
 
-	offers, err := q.inputSimulate.Simulate(*quoteRequest)
+	ctx := c.Request.Context()
+	metrics, err := q.inputMetrics.GetMetrics(ctx, filter)
 	if err != nil {
-		JSONErrorResponse(http.StatusInternalServerError, "Error ao Simular cotações", err, c)
+		q.logger.Log(ctx, errorLogLevel(err), "erro ao gerar métricas", slog.String("error", err.Error()))
+		JSONDomainErrorResponse(err, c)
 		return
 	}
 
-	offersResponse = DomainToSimulateQuoteResponse(offers)
-	if err = q.redisCache.Set(ctx, cachedKey, offersResponse, time.Minute*30); err != nil {
-		log.Println("Não foi possivel salvar retorno em cache err: ", err.Error())
+	switch metricsFormat(c) {
+	case MIMECSV:
+		body, err := MetricsToCSV(*metrics)
+		if err != nil {
+			q.logger.Log(ctx, errorLogLevel(err), "erro ao gerar csv das métricas", slog.String("error", err.Error()))
+			JSONDomainErrorResponse(err, c)
+			return
+		}
+		c.Data(http.StatusOK, contentTypeCSV, body)
+	case MIMEOpenMetrics:
+		c.Data(http.StatusOK, contentTypeOpenMetrics, MetricsToOpenMetrics(*metrics, true))
+	case MIMEPrometheus:
+		c.Data(http.StatusOK, contentTypePrometheus, MetricsToOpenMetrics(*metrics, false))
+	default:
+		c.JSON(http.StatusOK, DomainMetricsToRequest(*metrics))
 	}
-
-	c.JSON(http.StatusOK, offersResponse)
-	return
 }
 
-func (q *QuoteAdapterHandler) GetMetrics(c *gin.Context) {
-	lastQuotes, _ := strconv.Atoi(c.Query("last_quotes"))
-
-	metrics, err := q.inputMetrics.GetMetrics(lastQuotes)
+func (q *QuoteAdapterHandler) GetQuote(c *gin.Context) {
+	ctx := c.Request.Context()
+	quoteData, err := q.inputHistory.GetQuote(ctx, c.Param("id"))
 	if err != nil {
-		JSONErrorResponse(http.StatusInternalServerError, "Error ao gerar metricas", err, c)
+		if !errors.Is(err, quote.ErrQuoteNotFound) {
+			q.logger.Log(ctx, errorLogLevel(err), "erro ao buscar cotação", slog.String("quote_id", c.Param("id")), slog.String("error", err.Error()))
+		}
+		JSONDomainErrorResponse(err, c)
 		return
 	}
-	c.JSON(http.StatusOK, DomainMetricsToRequest(*metrics))
+	c.JSON(http.StatusOK, DomainToQuoteResponse(*quoteData))
 }
 
-func JSONErrorResponse(statusCode int, ErrorMessage string, error error, c *gin.Context) {
-	c.JSON(statusCode, struct {
-		ErrorMessage string `json:"errorMessage"`
-		ErrorDetails string `json:"errorDetails"`
-	}{ErrorMessage: ErrorMessage, ErrorDetails: error.Error()})
+func (q *QuoteAdapterHandler) ListQuotes(c *gin.Context) {
+	filter, err := QueryToQuoteFilter(c)
+	if err != nil {
+		JSONDomainErrorResponse(err, c)
+		return
+	}
+	ctx := c.Request.Context()
+	page, err := q.inputHistory.ListQuotes(ctx, filter)
+	if err != nil {
+		q.logger.Log(ctx, errorLogLevel(err), "erro ao listar cotações", slog.String("error", err.Error()))
+		JSONDomainErrorResponse(err, c)
+		return
+	}
+	c.JSON(http.StatusOK, DomainToQuoteListResponse(*page))
 }
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/health"
+	"net/http"
+)
+
+// ReadinessChecker verifica as dependências do serviço.
+type ReadinessChecker interface {
+	Check(ctx context.Context) health.Report
+}
+
+type DependencyStatusResponse struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type HealthResponse struct {
+	Status       string                     `json:"status"`
+	Dependencies []DependencyStatusResponse `json:"dependencies,omitempty"`
+}
+
+type HealthHandler struct {
+	checker ReadinessChecker
+}
+
+func NewHealthHandler(checker ReadinessChecker) *HealthHandler {
+	return &HealthHandler{checker: checker}
+}
+
+// Liveness só indica que o processo responde; não consulta dependências, para
+// que uma queda do banco não faça o orquestrador reiniciar o serviço.
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, HealthResponse{Status: health.StatusOK})
+}
+
+// Readiness responde 503 quando alguma dependência crítica está fora do ar.
+// Com falha só em dependências não críticas responde 200 com status degraded.
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	report := h.checker.Check(c.Request.Context())
+	status := http.StatusOK
+	if report.Status == health.StatusUnavailable {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, DomainToHealthResponse(report))
+}
+
+func DomainToHealthResponse(report health.Report) HealthResponse {
+	response := HealthResponse{Status: report.Status}
+	for _, d := range report.Dependencies {
+		response.Dependencies = append(response.Dependencies, DependencyStatusResponse{
+			Name:      d.Name,
+			Status:    d.Status,
+			Critical:  d.Critical,
+			LatencyMs: float64(d.Latency.Microseconds()) / 1000,
+			Error:     d.Error,
+		})
+	}
+	return response
+}
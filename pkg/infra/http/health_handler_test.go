@@ -0,0 +1,61 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/health"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func healthRouter(checks ...health.Check) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := NewHealthHandler(health.NewChecker(time.Second, checks...))
+	r := gin.New()
+	r.GET("/healthz", handler.Liveness)
+	r.GET("/readyz", handler.Readiness)
+	return r
+}
+
+func TestHealthHandlerReadiness(t *testing.T) {
+	databaseDown := func(ctx context.Context) error { return errors.New("connection refused") }
+	up := func(ctx context.Context) error { return nil }
+
+	tests := []struct {
+		name       string
+		checks     []health.Check
+		wantCode   int
+		wantStatus string
+	}{
+		{"tudo no ar", []health.Check{{Name: "postgres", Critical: true, Probe: up}}, http.StatusOK, health.StatusOK},
+		{"cache fora do ar", []health.Check{{Name: "postgres", Critical: true, Probe: up}, {Name: "cache", Probe: databaseDown}}, http.StatusOK, health.StatusDegraded},
+		{"banco fora do ar", []health.Check{{Name: "postgres", Critical: true, Probe: databaseDown}}, http.StatusServiceUnavailable, health.StatusUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			healthRouter(tt.checks...).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			var response HealthResponse
+			assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantStatus, response.Status)
+			assert.Len(t, response.Dependencies, len(tt.checks))
+		})
+	}
+}
+
+func TestHealthHandlerLivenessIgnoresDependencies(t *testing.T) {
+	rec := httptest.NewRecorder()
+	healthRouter(health.Check{Name: "postgres", Critical: true, Probe: func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+}
@@ -1,6 +1,11 @@
 package http
 
-import "github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
+	"strconv"
+	"time"
+)
 
 type CarrierMetricsResponse struct {
 	Name       string
@@ -9,6 +14,16 @@ type CarrierMetricsResponse struct {
 	MinPrice   float64
 	TotalPrice float64
 	TotalOffer int
+
+	P50Price      float64
+	P90Price      float64
+	P99Price      float64
+	StdDevPrice   float64
+	AvgPricePerKg float64
+
+	AvgDeliveryTime float64
+	MinDeliveryTime int
+	MaxDeliveryTime int
 }
 
 type MetricsResponse struct {
@@ -18,26 +33,94 @@ type MetricsResponse struct {
 	GeneralMaxPrice       float64
 	GeneralMinCarrierName string
 	GeneralMaxCarrierName string
+	Series                []MetricsBucketResponse `json:",omitempty"`
+	Regions               []RegionMetricsResponse `json:",omitempty"`
+}
+
+type RegionCarrierMetricsResponse struct {
+	Name            string
+	TotalOffer      int
+	AvgPrice        float64
+	AvgDeliveryTime float64
+}
+
+type RegionMetricsResponse struct {
+	Region     string
+	TotalOffer int
+	Carrier    []RegionCarrierMetricsResponse
+	Cheapest   *RegionCarrierMetricsResponse
+	Fastest    *RegionCarrierMetricsResponse
+}
+
+type MetricsBucketResponse struct {
+	PeriodStart time.Time
+	Carrier     []CarrierMetricsResponse
+}
+
+func domainToCarrierMetrics(carriers []quote.CarrierMetrics) []CarrierMetricsResponse {
+	var carrierMetris []CarrierMetricsResponse
+	for _, m := range carriers {
+		metricDomain := CarrierMetricsResponse{
+			Name:       m.Name,
+			AvgPrice:   m.AvgPrice,
+			MaxPrice:   m.MaxPrice,
+			MinPrice:   m.MinPrice,
+			TotalPrice: m.TotalPrice,
+			TotalOffer: m.TotalOffer,
+
+			P50Price:      m.P50Price,
+			P90Price:      m.P90Price,
+			P99Price:      m.P99Price,
+			StdDevPrice:   m.StdDevPrice,
+			AvgPricePerKg: m.AvgPricePerKg,
+
+			AvgDeliveryTime: m.AvgDeliveryTime,
+			MinDeliveryTime: m.MinDeliveryTime,
+			MaxDeliveryTime: m.MaxDeliveryTime,
+		}
+		carrierMetris = append(carrierMetris, metricDomain)
+
+	}
+	return carrierMetris
+}
+
+func domainToRegionCarrier(carrier *quote.RegionCarrierMetrics) *RegionCarrierMetricsResponse {
+	if carrier == nil {
+		return nil
+	}
+	return &RegionCarrierMetricsResponse{
+		Name:            carrier.Name,
+		TotalOffer:      carrier.TotalOffer,
+		AvgPrice:        carrier.AvgPrice,
+		AvgDeliveryTime: carrier.AvgDeliveryTime,
+	}
 }
 
 func DomainMetricsToRequest(metrics quote.Metrics) MetricsResponse {
+	var regions []RegionMetricsResponse
+	for _, region := range metrics.Regions {
+		regionResponse := RegionMetricsResponse{
+			Region:     region.Region,
+			TotalOffer: region.TotalOffer,
+			Cheapest:   domainToRegionCarrier(region.Cheapest),
+			Fastest:    domainToRegionCarrier(region.Fastest),
+		}
+		for i := range region.Carrier {
+			regionResponse.Carrier = append(regionResponse.Carrier, *domainToRegionCarrier(&region.Carrier[i]))
+		}
+		regions = append(regions, regionResponse)
+	}
+	var series []MetricsBucketResponse
+	for _, bucket := range metrics.Series {
+		series = append(series, MetricsBucketResponse{
+			PeriodStart: bucket.PeriodStart,
+			Carrier:     domainToCarrierMetrics(bucket.Carrier),
+		})
+	}
 	return MetricsResponse{
-		Carrier: func() []CarrierMetricsResponse {
-			var carrierMetris []CarrierMetricsResponse
-			for _, m := range metrics.Carrier {
-				metricDomain := CarrierMetricsResponse{
-					Name:       m.Name,
-					AvgPrice:   m.AvgPrice,
-					MaxPrice:   m.MaxPrice,
-					MinPrice:   m.MinPrice,
-					TotalPrice: m.TotalPrice,
-					TotalOffer: m.TotalOffer,
-				}
-				carrierMetris = append(carrierMetris, metricDomain)
-
-			}
-			return carrierMetris
-		}(),
+		Carrier:               domainToCarrierMetrics(metrics.Carrier),
+		Series:                series,
+		Regions:               regions,
 		GeneralMinCarrierName: metrics.GeneralMinCarrierName,
 		GeneralMaxCarrierName: metrics.GeneralMaxCarrierName,
 		GeneralMinPrice:       metrics.GeneralMinPrice,
@@ -45,3 +128,33 @@ func DomainMetricsToRequest(metrics quote.Metrics) MetricsResponse {
 		GeneralAvgPrice:       metrics.GeneralAvgPrice,
 	}
 }
+
+// QueryToMetricsFilter lê os filtros de GET /metrics. from e to aceitam os
+// mesmos formatos do histórico de cotações.
+func QueryToMetricsFilter(c *gin.Context) (quote.MetricsFilter, error) {
+	filter := quote.MetricsFilter{
+		Carrier: c.Query("carrier"),
+		Service: c.Query("service"),
+		GroupBy: quote.MetricsGranularity(c.Query("group_by")),
+
+		RegionBy: quote.MetricsRegionGrouping(c.Query("region_by")),
+	}
+	var err error
+	if lastQuotes := c.Query("last_quotes"); lastQuotes != "" {
+		if filter.LastQuotes, err = strconv.Atoi(lastQuotes); err != nil {
+			return filter, &quote.ValidationError{Field: "last_quotes", Message: "last_quotes deve ser um número inteiro"}
+		}
+	}
+	if prefixLength := c.Query("cep_prefix_length"); prefixLength != "" {
+		if filter.CEPPrefixLength, err = strconv.Atoi(prefixLength); err != nil {
+			return filter, &quote.ValidationError{Field: "cep_prefix_length", Message: "cep_prefix_length deve ser um número inteiro"}
+		}
+	}
+	if filter.From, err = parseQueryDate(c.Query("from"), false); err != nil {
+		return filter, &quote.ValidationError{Field: "from", Message: err.Error()}
+	}
+	if filter.To, err = parseQueryDate(c.Query("to"), true); err != nil {
+		return filter, &quote.ValidationError{Field: "to", Message: err.Error()}
+	}
+	return filter, nil
+}
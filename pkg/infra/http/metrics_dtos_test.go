@@ -0,0 +1,46 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func metricsContext(rawQuery string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/metrics?"+rawQuery, nil)
+	return c
+}
+
+func TestQueryToMetricsFilter(t *testing.T) {
+	filter, err := QueryToMetricsFilter(metricsContext("from=2025-04-01&to=2025-04-30&carrier=CORREIOS&service=SEDEX&group_by=week&last_quotes=10"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), filter.From)
+	assert.Equal(t, time.Date(2025, 4, 30, 23, 59, 59, 999999999, time.UTC), filter.To)
+	assert.Equal(t, "CORREIOS", filter.Carrier)
+	assert.Equal(t, "SEDEX", filter.Service)
+	assert.Equal(t, quote.MetricsByWeek, filter.GroupBy)
+	assert.Equal(t, 10, filter.LastQuotes)
+
+	_, err = QueryToMetricsFilter(metricsContext("from=ontem"))
+	var validationErr *quote.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "from", validationErr.Field)
+}
+
+func TestDomainMetricsToRequestSeries(t *testing.T) {
+	april := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	response := DomainMetricsToRequest(quote.Metrics{
+		Series: []quote.MetricsBucket{
+			{PeriodStart: april, Carrier: []quote.CarrierMetrics{{Name: "CORREIOS", AvgPrice: 30, TotalOffer: 2}}},
+		},
+	})
+
+	assert.Equal(t, 1, len(response.Series))
+	assert.Equal(t, april, response.Series[0].PeriodStart)
+	assert.Equal(t, "CORREIOS", response.Series[0].Carrier[0].Name)
+}
@@ -0,0 +1,153 @@
+package http
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	MIMECSV         = "text/csv"
+	MIMEOpenMetrics = "application/openmetrics-text"
+	MIMEPrometheus  = "text/plain"
+
+	contentTypeCSV         = "text/csv; charset=utf-8"
+	contentTypeOpenMetrics = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+	contentTypePrometheus  = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// metricsFormat escolhe o formato de GET /metrics. O parâmetro format
+// (json, csv ou openmetrics) tem prioridade sobre o cabeçalho Accept, para
+// ferramentas que não conseguem enviar cabeçalhos.
+func metricsFormat(c *gin.Context) string {
+	switch c.Query("format") {
+	case "csv":
+		return MIMECSV
+	case "openmetrics":
+		return MIMEOpenMetrics
+	case "json":
+		return gin.MIMEJSON
+	}
+	return c.NegotiateFormat(gin.MIMEJSON, MIMECSV, MIMEOpenMetrics, MIMEPrometheus)
+}
+
+var carrierCSVHeader = []string{
+	"carrier", "total_offer", "total_price", "avg_price", "min_price", "max_price",
+	"p50_price", "p90_price", "p99_price", "stddev_price", "avg_price_per_kg",
+	"avg_delivery_time", "min_delivery_time", "max_delivery_time",
+}
+
+func carrierCSVRow(m quote.CarrierMetrics) []string {
+	return []string{
+		m.Name,
+		strconv.Itoa(m.TotalOffer),
+		formatFloat(m.TotalPrice),
+		formatFloat(m.AvgPrice),
+		formatFloat(m.MinPrice),
+		formatFloat(m.MaxPrice),
+		formatFloat(m.P50Price),
+		formatFloat(m.P90Price),
+		formatFloat(m.P99Price),
+		formatFloat(m.StdDevPrice),
+		formatFloat(m.AvgPricePerKg),
+		formatFloat(m.AvgDeliveryTime),
+		strconv.Itoa(m.MinDeliveryTime),
+		strconv.Itoa(m.MaxDeliveryTime),
+	}
+}
+
+// MetricsToCSV gera uma linha por transportadora. Quando as métricas vêm
+// agrupadas por período, gera uma linha por período e transportadora, com a
+// data de início do período na primeira coluna.
+func MetricsToCSV(metrics quote.Metrics) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if len(metrics.Series) > 0 {
+		w.Write(append([]string{"period_start"}, carrierCSVHeader...))
+		for _, bucket := range metrics.Series {
+			for _, m := range bucket.Carrier {
+				w.Write(append([]string{bucket.PeriodStart.Format(time.DateOnly)}, carrierCSVRow(m)...))
+			}
+		}
+	} else {
+		w.Write(carrierCSVHeader)
+		for _, m := range metrics.Carrier {
+			w.Write(carrierCSVRow(m))
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+type metricFamily struct {
+	name  string
+	help  string
+	value func(m quote.CarrierMetrics) float64
+}
+
+var carrierMetricFamilies = []metricFamily{
+	{"freight_carrier_offers", "Ofertas recebidas da transportadora.", func(m quote.CarrierMetrics) float64 { return float64(m.TotalOffer) }},
+	{"freight_carrier_price_sum", "Soma dos preços ofertados, em reais.", func(m quote.CarrierMetrics) float64 { return m.TotalPrice }},
+	{"freight_carrier_price_avg", "Preço médio ofertado, em reais.", func(m quote.CarrierMetrics) float64 { return m.AvgPrice }},
+	{"freight_carrier_price_min", "Menor preço ofertado, em reais.", func(m quote.CarrierMetrics) float64 { return m.MinPrice }},
+	{"freight_carrier_price_max", "Maior preço ofertado, em reais.", func(m quote.CarrierMetrics) float64 { return m.MaxPrice }},
+	{"freight_carrier_price_stddev", "Desvio padrão dos preços ofertados, em reais.", func(m quote.CarrierMetrics) float64 { return m.StdDevPrice }},
+	{"freight_carrier_price_per_kg_avg", "Preço médio por quilo, em reais.", func(m quote.CarrierMetrics) float64 { return m.AvgPricePerKg }},
+	{"freight_carrier_delivery_days_avg", "Prazo médio de entrega, em dias.", func(m quote.CarrierMetrics) float64 { return m.AvgDeliveryTime }},
+	{"freight_carrier_delivery_days_min", "Menor prazo de entrega, em dias.", func(m quote.CarrierMetrics) float64 { return float64(m.MinDeliveryTime) }},
+	{"freight_carrier_delivery_days_max", "Maior prazo de entrega, em dias.", func(m quote.CarrierMetrics) float64 { return float64(m.MaxDeliveryTime) }},
+}
+
+// MetricsToOpenMetrics expõe os agregados como gauges, com a transportadora
+// no label carrier. Com openMetrics falso o texto segue o formato clássico do
+// Prometheus (sem o "# EOF" final).
+func MetricsToOpenMetrics(metrics quote.Metrics, openMetrics bool) []byte {
+	var buf bytes.Buffer
+	writeFamily := func(name, help string) {
+		fmt.Fprintf(&buf, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	}
+
+	for _, family := range carrierMetricFamilies {
+		writeFamily(family.name, family.help)
+		for _, m := range metrics.Carrier {
+			fmt.Fprintf(&buf, "%s{carrier=\"%s\"} %s\n", family.name, escapeLabel(m.Name), formatFloat(family.value(m)))
+		}
+	}
+
+	writeFamily("freight_carrier_price_quantile", "Percentis do preço ofertado, em reais.")
+	for _, m := range metrics.Carrier {
+		for _, q := range []struct {
+			quantile string
+			value    float64
+		}{{"0.5", m.P50Price}, {"0.9", m.P90Price}, {"0.99", m.P99Price}} {
+			fmt.Fprintf(&buf, "freight_carrier_price_quantile{carrier=\"%s\",quantile=\"%s\"} %s\n", escapeLabel(m.Name), q.quantile, formatFloat(q.value))
+		}
+	}
+
+	writeFamily("freight_general_price_avg", "Preço médio de todas as ofertas, em reais.")
+	fmt.Fprintf(&buf, "freight_general_price_avg %s\n", formatFloat(metrics.GeneralAvgPrice))
+	writeFamily("freight_general_price_min", "Menor preço entre todas as ofertas, em reais.")
+	fmt.Fprintf(&buf, "freight_general_price_min{carrier=\"%s\"} %s\n", escapeLabel(metrics.GeneralMinCarrierName), formatFloat(metrics.GeneralMinPrice))
+	writeFamily("freight_general_price_max", "Maior preço entre todas as ofertas, em reais.")
+	fmt.Fprintf(&buf, "freight_general_price_max{carrier=\"%s\"} %s\n", escapeLabel(metrics.GeneralMaxCarrierName), formatFloat(metrics.GeneralMaxPrice))
+
+	if openMetrics {
+		buf.WriteString("# EOF\n")
+	}
+	return buf.Bytes()
+}
+
+var labelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelReplacer.Replace(value)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func exportMetrics() quote.Metrics {
+	return quote.Metrics{
+		Carrier: []quote.CarrierMetrics{
+			{Name: "CORREIOS", TotalOffer: 2, TotalPrice: 61.5, AvgPrice: 30.75, MinPrice: 28, MaxPrice: 33.5, P50Price: 30.75, MinDeliveryTime: 1, MaxDeliveryTime: 3},
+			{Name: `JAD "LOG"`, TotalOffer: 1, TotalPrice: 25, AvgPrice: 25, MinPrice: 25, MaxPrice: 25},
+		},
+		GeneralAvgPrice:       28.83,
+		GeneralMinPrice:       25,
+		GeneralMaxPrice:       33.5,
+		GeneralMinCarrierName: `JAD "LOG"`,
+		GeneralMaxCarrierName: "CORREIOS",
+	}
+}
+
+func TestMetricsToCSV(t *testing.T) {
+	body, err := MetricsToCSV(exportMetrics())
+
+	assert.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	assert.Equal(t, 3, len(lines))
+	assert.True(t, strings.HasPrefix(lines[0], "carrier,total_offer,total_price,avg_price"))
+	assert.True(t, strings.HasPrefix(lines[1], "CORREIOS,2,61.5,30.75,28,33.5,30.75"))
+	assert.True(t, strings.HasPrefix(lines[2], `"JAD ""LOG""",1,25`))
+}
+
+func TestMetricsToCSVSeries(t *testing.T) {
+	metrics := quote.Metrics{Series: []quote.MetricsBucket{
+		{PeriodStart: time.Date(2025, 4, 7, 0, 0, 0, 0, time.UTC), Carrier: []quote.CarrierMetrics{{Name: "CORREIOS", TotalOffer: 2}}},
+	}}
+
+	body, err := MetricsToCSV(metrics)
+
+	assert.Nil(t, err)
+	assert.Contains(t, string(body), "period_start,carrier,")
+	assert.Contains(t, string(body), "2025-04-07,CORREIOS,2,")
+}
+
+func TestMetricsToOpenMetrics(t *testing.T) {
+	body := string(MetricsToOpenMetrics(exportMetrics(), true))
+
+	assert.Contains(t, body, "# TYPE freight_carrier_price_avg gauge\n")
+	assert.Contains(t, body, "freight_carrier_price_avg{carrier=\"CORREIOS\"} 30.75\n")
+	assert.Contains(t, body, `freight_carrier_offers{carrier="JAD \"LOG\""} 1`)
+	assert.Contains(t, body, "freight_carrier_price_quantile{carrier=\"CORREIOS\",quantile=\"0.5\"} 30.75\n")
+	assert.Contains(t, body, "freight_general_price_avg 28.83\n")
+	assert.True(t, strings.HasSuffix(body, "# EOF\n"))
+
+	assert.False(t, strings.Contains(string(MetricsToOpenMetrics(exportMetrics(), false)), "# EOF"))
+}
+
+func TestMetricsFormatNegotiation(t *testing.T) {
+	tests := []struct {
+		accept string
+		query  string
+		format string
+	}{
+		{"", "", gin.MIMEJSON},
+		{"application/json", "", gin.MIMEJSON},
+		{"text/csv", "", MIMECSV},
+		{"application/openmetrics-text; version=1.0.0", "", MIMEOpenMetrics},
+		{"text/plain", "", MIMEPrometheus},
+		{"application/json", "format=csv", MIMECSV},
+	}
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/metrics?"+tt.query, nil)
+		if tt.accept != "" {
+			c.Request.Header.Set("Accept", tt.accept)
+		}
+		assert.Equal(t, tt.format, metricsFormat(c), "Accept %q, query %q", tt.accept, tt.query)
+	}
+}
@@ -0,0 +1,189 @@
+package http
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type QuoteVolumeResponse struct {
+	Sku           string  `json:"sku,omitempty"`
+	Category      string  `json:"category"`
+	Amount        int     `json:"amount"`
+	UnitaryWeight float64 `json:"unitary_weight"`
+	Price         float64 `json:"price"`
+	Height        float64 `json:"height"`
+	Width         float64 `json:"width"`
+	Length        float64 `json:"length"`
+}
+
+type QuoteDispatcherResponse struct {
+	WarehouseID string                `json:"warehouse_id"`
+	Zipcode     string                `json:"zipcode"`
+	Volumes     []QuoteVolumeResponse `json:"volumes"`
+}
+
+type QuoteRequestResponse struct {
+	RecipientType             int                       `json:"recipient_type"`
+	RecipientCountry          string                    `json:"recipient_country"`
+	RecipientZipcode          string                    `json:"recipient_zipcode"`
+	RecipientRegisteredNumber string                    `json:"recipient_registered_number,omitempty"`
+	Dispatchers               []QuoteDispatcherResponse `json:"dispatchers"`
+}
+
+type QuoteResponse struct {
+	ID                string               `json:"id"`
+	RequestID         string               `json:"request_id,omitempty"`
+	Status            string               `json:"status"`
+	Cached            bool                 `json:"cached"`
+	CreatedAt         time.Time            `json:"created_at"`
+	UpstreamLatencyMs int64                `json:"upstream_latency_ms"`
+	TotalWeight       float64              `json:"total_weight"`
+	DeclaredValue     float64              `json:"declared_value"`
+	Request           QuoteRequestResponse `json:"request"`
+	Carrier           []Carrier            `json:"carrier"`
+}
+
+type QuoteListResponse struct {
+	Quotes     []QuoteResponse `json:"quotes"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+func formatZipcode(zipcode int) string {
+	return fmt.Sprintf("%08d", zipcode)
+}
+
+// maskRegisteredNumber esconde o CPF de um destinatário pessoa física,
+// deixando só os dígitos do meio (***.456.789-**), o suficiente para o
+// suporte conferir com o cliente. CNPJ não é dado pessoal e volta inteiro.
+func maskRegisteredNumber(recipient quote.Recipient) string {
+	number := quote.NormalizeRegisteredNumber(recipient.RegisteredNumber)
+	if recipient.Type != 0 || number == "" {
+		return number
+	}
+	if len(number) != 11 {
+		return "***"
+	}
+	return "***." + number[3:6] + "." + number[6:9] + "-**"
+}
+
+func DomainToQuoteResponse(q quote.Quote) QuoteResponse {
+	var dispatchers []QuoteDispatcherResponse
+	for _, d := range q.Request.Dispatchers {
+		var volumes []QuoteVolumeResponse
+		for _, v := range d.Volumes {
+			volumes = append(volumes, QuoteVolumeResponse{
+				Sku:           v.SKU,
+				Category:      v.Category,
+				Amount:        v.Amount,
+				UnitaryWeight: v.UnitaryWeight,
+				Price:         v.UnitaryPrice,
+				Height:        v.Height,
+				Width:         v.Width,
+				Length:        v.Length,
+			})
+		}
+		dispatchers = append(dispatchers, QuoteDispatcherResponse{
+			WarehouseID: d.WarehouseID,
+			Zipcode:     formatZipcode(d.Zipcode),
+			Volumes:     volumes,
+		})
+	}
+	return QuoteResponse{
+		ID:                q.ID,
+		RequestID:         q.RequestID,
+		Status:            string(q.Status),
+		Cached:            q.Cached,
+		CreatedAt:         q.CreatedAt,
+		UpstreamLatencyMs: q.UpstreamLatency.Milliseconds(),
+		TotalWeight:       q.TotalWeight(),
+		DeclaredValue:     q.DeclaredValue(),
+		Request: QuoteRequestResponse{
+			RecipientType:             q.Request.Recipient.Type,
+			RecipientCountry:          q.Request.Recipient.Country,
+			RecipientZipcode:          formatZipcode(q.Request.Recipient.Zipcode),
+			RecipientRegisteredNumber: maskRegisteredNumber(q.Request.Recipient),
+			Dispatchers:               dispatchers,
+		},
+		Carrier: domainToCarriers(q.Offers),
+	}
+}
+
+func DomainToQuoteListResponse(page quote.QuotePage) QuoteListResponse {
+	quotes := make([]QuoteResponse, 0, len(page.Quotes))
+	for _, q := range page.Quotes {
+		quotes = append(quotes, DomainToQuoteResponse(q))
+	}
+	return QuoteListResponse{Quotes: quotes, NextCursor: page.NextCursor}
+}
+
+// QueryToQuoteFilter lê os filtros do histórico da query string. Datas aceitam
+// RFC3339 ou YYYY-MM-DD; uma data sem horário em "to" inclui o dia inteiro.
+// Erros de formato voltam como ValidationError com o nome do parâmetro.
+func QueryToQuoteFilter(c *gin.Context) (quote.QuoteFilter, error) {
+	var filter quote.QuoteFilter
+	var err error
+
+	if filter.From, err = parseQueryDate(c.Query("from"), false); err != nil {
+		return filter, &quote.ValidationError{Field: "from", Message: err.Error()}
+	}
+	if filter.To, err = parseQueryDate(c.Query("to"), true); err != nil {
+		return filter, &quote.ValidationError{Field: "to", Message: err.Error()}
+	}
+	if zipcode := c.Query("zipcode"); zipcode != "" {
+		if filter.RecipientZipcode, err = ConverterStrinToInZipcode(strings.ReplaceAll(zipcode, "-", "")); err != nil {
+			return filter, &quote.ValidationError{Field: "zipcode", Message: err.Error()}
+		}
+	}
+	filter.Carrier = c.Query("carrier")
+	if filter.MinPrice, err = parseQueryPrice(c.Query("min_price")); err != nil {
+		return filter, &quote.ValidationError{Field: "min_price", Message: err.Error()}
+	}
+	if filter.MaxPrice, err = parseQueryPrice(c.Query("max_price")); err != nil {
+		return filter, &quote.ValidationError{Field: "max_price", Message: err.Error()}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit <= 0 {
+			return filter, &quote.ValidationError{Field: "limit", Message: "limit deve ser um inteiro positivo"}
+		}
+	}
+	if cursor := c.Query("cursor"); cursor != "" {
+		if filter.After, err = quote.DecodeQuoteCursor(cursor); err != nil {
+			return filter, err
+		}
+	}
+	return filter, nil
+}
+
+func parseQueryDate(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("data deve estar no formato RFC3339 ou YYYY-MM-DD mas foi enviado %s", value)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+func parseQueryPrice(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	price, err := strconv.ParseFloat(value, 64)
+	// ParseFloat aceita "NaN" e "Inf", que não são preços
+	if err != nil || math.IsNaN(price) || math.IsInf(price, 0) || price < 0 {
+		return nil, fmt.Errorf("preço deve ser um número não negativo mas foi enviado %s", value)
+	}
+	return &price, nil
+}
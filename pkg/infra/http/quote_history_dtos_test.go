@@ -0,0 +1,51 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDomainToQuoteResponseReturnsRecipient(t *testing.T) {
+	tests := []struct {
+		name      string
+		recipient quote.Recipient
+		expected  string
+	}{
+		{"CPF mascarado", quote.Recipient{Type: 0, Country: "BRA", Zipcode: 1311000, RegisteredNumber: "52998224725"}, "***.982.247-**"},
+		{"CNPJ inteiro", quote.Recipient{Type: 1, Country: "BRA", Zipcode: 1311000, RegisteredNumber: "11222333000181"}, "11222333000181"},
+		{"sem documento", quote.Recipient{Type: 0, Country: "BRA", Zipcode: 1311000}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := DomainToQuoteResponse(quote.Quote{ID: "q-1", Request: quote.QuoteRequest{Recipient: tt.recipient}})
+
+			assert.Equal(t, tt.recipient.Type, response.Request.RecipientType)
+			assert.Equal(t, "BRA", response.Request.RecipientCountry)
+			assert.Equal(t, "01311000", response.Request.RecipientZipcode)
+			assert.Equal(t, tt.expected, response.Request.RecipientRegisteredNumber)
+		})
+	}
+}
+
+func TestQueryToQuoteFilterRejectsNonFinitePrices(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, value := range []string{"NaN", "Inf", "-Inf", "+Inf", "abc", "-1"} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/quotes?min_price="+value, nil)
+
+		_, err := QueryToQuoteFilter(c)
+
+		var validationErr *quote.ValidationError
+		assert.ErrorAs(t, err, &validationErr, value)
+		assert.Equal(t, "min_price", validationErr.Field)
+	}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/quotes?max_price=99.9", nil)
+	filter, err := QueryToQuoteFilter(c)
+	assert.Nil(t, err)
+	assert.Equal(t, 99.9, *filter.MaxPrice)
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/logging"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	HeaderRequestID = "X-Request-ID"
+
+	maxRequestIDLength = 128
+)
+
+// RequestLogMiddleware lê o X-Request-ID recebido (ou gera um), devolve-o na
+// resposta e o coloca em todas as linhas de log da requisição, junto com a
+// rota. Ao final escreve o log de acesso com status e latência, no lugar do
+// log padrão do gin.
+func RequestLogMiddleware(logger *slog.Logger) gin.HandlerFunc {
+	logger = logging.OrDefault(logger)
+	return func(c *gin.Context) {
+		start := time.Now()
+		requestID := c.GetHeader(HeaderRequestID)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		c.Header(HeaderRequestID, requestID)
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx := logging.NewContext(logging.WithRequestID(c.Request.Context(), requestID),
+			slog.String("request_id", requestID),
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+		)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status == StatusClientClosedRequest:
+			// o cliente desistiu; não é erro dele nem do serviço
+			level = slog.LevelInfo
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		logger.LogAttrs(c.Request.Context(), level, "requisição atendida",
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("client_ip", c.ClientIP()),
+		)
+	}
+}
+
+// validRequestID aceita só ASCII visível e um tamanho limitado, para que o
+// valor recebido do cliente não quebre nem infle as linhas de log.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
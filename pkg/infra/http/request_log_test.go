@@ -0,0 +1,80 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/logging"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func requestLogRouter(buf *bytes.Buffer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	logger, _ := logging.New(buf, logging.FormatJSON, "info")
+	r := gin.New()
+	r.Use(RequestLogMiddleware(logger))
+	r.GET("/quotes/:id", func(c *gin.Context) {
+		logging.AddAttrs(c.Request.Context(), slog.Int("zipcode", 1311000))
+		c.Status(http.StatusNotFound)
+	})
+	return r
+}
+
+func TestRequestLogMiddlewareKeepsValidRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	r := requestLogRouter(&buf)
+
+	req := httptest.NewRequest(http.MethodGet, "/quotes/q-1", nil)
+	req.Header.Set(HeaderRequestID, "checkout-42")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, "checkout-42", rec.Header().Get(HeaderRequestID))
+	var line map[string]any
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "WARN", line["level"])
+	assert.Equal(t, "checkout-42", line["request_id"])
+	assert.Equal(t, "/quotes/:id", line["route"])
+	assert.Equal(t, float64(1311000), line["zipcode"])
+	assert.Equal(t, float64(http.StatusNotFound), line["status"])
+	assert.Contains(t, line, "latency_ms")
+}
+
+func TestRequestLogMiddlewareGeneratesRequestID(t *testing.T) {
+	for _, received := range []string{"", "com espaço", strings.Repeat("a", maxRequestIDLength+1)} {
+		var buf bytes.Buffer
+		r := requestLogRouter(&buf)
+
+		req := httptest.NewRequest(http.MethodGet, "/quotes/q-1", nil)
+		req.Header.Set(HeaderRequestID, received)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		generated := rec.Header().Get(HeaderRequestID)
+		assert.Len(t, generated, 32, received)
+		assert.Contains(t, buf.String(), generated)
+	}
+}
+
+func TestRequestLogMiddlewareLogsCanceledRequestsAtInfo(t *testing.T) {
+	var buf bytes.Buffer
+	gin.SetMode(gin.TestMode)
+	logger, _ := logging.New(&buf, logging.FormatJSON, "info")
+	r := gin.New()
+	r.Use(RequestLogMiddleware(logger))
+	r.POST("/simulate", func(c *gin.Context) {
+		c.Status(StatusClientClosedRequest)
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/simulate", nil))
+
+	var line map[string]any
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "INFO", line["level"])
+	assert.Equal(t, float64(StatusClientClosedRequest), line["status"])
+}
@@ -0,0 +1,36 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewServerAppliesConfig(t *testing.T) {
+	server := NewServer(gin.New(), ServerConfig{
+		Port:           "9090",
+		ReadTimeout:    5 * time.Second,
+		WriteTimeout:   90 * time.Second,
+		IdleTimeout:    time.Minute,
+		MaxHeaderBytes: 8 << 10,
+	})
+
+	assert.Equal(t, ":9090", server.Addr)
+	assert.Equal(t, 5*time.Second, server.ReadTimeout)
+	assert.Equal(t, 5*time.Second, server.ReadHeaderTimeout)
+	assert.Equal(t, 90*time.Second, server.WriteTimeout)
+	assert.Equal(t, time.Minute, server.IdleTimeout)
+	assert.Equal(t, 8<<10, server.MaxHeaderBytes)
+}
+
+func TestNewServerDefaults(t *testing.T) {
+	server := NewServer(gin.New(), ServerConfig{})
+
+	assert.Equal(t, ":8000", server.Addr)
+	assert.Equal(t, 15*time.Second, server.ReadTimeout)
+	assert.Equal(t, 60*time.Second, server.WriteTimeout)
+	assert.Equal(t, 120*time.Second, server.IdleTimeout)
+	assert.Equal(t, http.DefaultMaxHeaderBytes, server.MaxHeaderBytes)
+}
@@ -2,7 +2,6 @@ package http
 
 import (
 	"fmt"
-	"github.com/pgabrielgmdeveloper/freightQuote/configs"
 	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
 	"strconv"
 	"strings"
@@ -12,8 +11,12 @@ type Address struct {
 	Zipcode string `json:"zipcode" binding:"required"`
 }
 
+// RecipientRequest aceita o tipo do destinatário (0 = PF, 1 = PJ; padrão PF)
+// e, opcionalmente, o CPF ou CNPJ, que a Frete Rápido usa no cálculo.
 type RecipientRequest struct {
-	Address Address `json:"address"`
+	Type             int     `json:"type"`
+	RegisteredNumber string  `json:"registered_number"`
+	Address          Address `json:"address"`
 }
 
 type VolumeRequest struct {
@@ -25,22 +28,45 @@ type VolumeRequest struct {
 	Height        float64 `json:"height" binding:"required, gt=0"`
 	Width         float64 `json:"width" binding:"required, gt=0"`
 	Length        float64 `json:"length" binding:"required, gt=0"`
+	WarehouseID   string  `json:"warehouse_id"`
+}
+
+type OriginRequest struct {
+	WarehouseID string `json:"warehouse_id"`
 }
 
 type SimulateQuoteRequest struct {
 	Recipient RecipientRequest `json:"recipient" binding:"required"`
+	Origin    OriginRequest    `json:"origin"`
 	Volumes   []VolumeRequest  `json:"volumes" binding:"required"`
 }
 
 type Carrier struct {
-	Name     string  `json:"name"`
-	Service  string  `json:"service"`
-	Deadline int     `json:"deadline"`
-	Price    float64 `json:"price"`
+	Name        string  `json:"name"`
+	Service     string  `json:"service"`
+	Deadline    int     `json:"deadline"`
+	Price       float64 `json:"price"`
+	Source      string  `json:"source"`
+	WarehouseID string  `json:"warehouse_id,omitempty"`
+}
+
+type DispatcherOffersResponse struct {
+	WarehouseID string    `json:"warehouse_id"`
+	Carrier     []Carrier `json:"carrier"`
+}
+
+type CombinationResponse struct {
+	Price    float64   `json:"price"`
+	Deadline int       `json:"deadline"`
+	Carrier  []Carrier `json:"carrier"`
 }
 
 type SimulateQuoteResponse struct {
-	Carrier []Carrier `json:"carrier"`
+	QuoteID     string                     `json:"quote_id,omitempty"`
+	Carrier     []Carrier                  `json:"carrier"`
+	Dispatchers []DispatcherOffersResponse `json:"dispatchers,omitempty"`
+	Cheapest    *CombinationResponse       `json:"cheapest,omitempty"`
+	Fastest     *CombinationResponse       `json:"fastest,omitempty"`
 }
 
 func ConverterStrinToInZipcode(zipcode string) (int, error) {
@@ -51,64 +77,102 @@ func ConverterStrinToInZipcode(zipcode string) (int, error) {
 	return zipcodeResponse, nil
 }
 
-func RequestToDomainQuote(request SimulateQuoteRequest) (*quote.QuoteRequest, error) {
-	cfg, err := configs.LoadConfig()
+// RequestToDomainQuote monta um Dispatcher por centro de distribuição, na
+// ordem em que aparecem nos volumes. Volumes sem warehouse_id saem de
+// defaultOrigin; os demais precisam estar em origins. O remetente vem da
+// configuração carregada na subida.
+func RequestToDomainQuote(request SimulateQuoteRequest, shipper quote.Shipper, defaultOrigin quote.Warehouse, origins map[string]quote.Warehouse) (*quote.QuoteRequest, error) {
+	zipcode, err := ConverterStrinToInZipcode(request.Recipient.Address.Zipcode)
 	if err != nil {
 		return nil, err
 	}
 
-	zipcode, err := ConverterStrinToInZipcode(request.Recipient.Address.Zipcode)
-	if err != nil {
-		return nil, err
+	var dispatchers []quote.Dispatcher
+	dispatcherIndex := make(map[string]int)
+	for i, v := range request.Volumes {
+		origin := defaultOrigin
+		if v.WarehouseID != "" {
+			warehouse, ok := origins[v.WarehouseID]
+			if !ok {
+				return nil, &quote.ValidationError{
+					Field:   fmt.Sprintf("volumes[%d].warehouse_id", i),
+					Message: "centro de distribuição não encontrado ou inativo",
+				}
+			}
+			origin = warehouse
+		}
+		i, ok := dispatcherIndex[origin.ID]
+		if !ok {
+			i = len(dispatchers)
+			dispatcherIndex[origin.ID] = i
+			dispatchers = append(dispatchers, origin.ToDispatcher(nil))
+		}
+		dispatchers[i].Volumes = append(dispatchers[i].Volumes, quote.Volume{
+			SKU:           v.Sku,
+			Category:      strconv.Itoa(v.Category),
+			Amount:        v.Amount,
+			UnitaryWeight: v.UnitaryWeight,
+			Width:         v.Width,
+			Height:        v.Height,
+			Length:        v.Length,
+			UnitaryPrice:  v.Price,
+		})
 	}
+
 	return &quote.QuoteRequest{
-		Shipper: quote.Shipper{
-			RegisteredNumber: cfg.RegisteredNumber,
-			Token:            cfg.TokenAPI,
-			PlatformCode:     cfg.PlatformCode,
-		},
+		Shipper: shipper,
 		Recipient: quote.Recipient{
-			Type:    0,
-			Country: "BRA",
-			Zipcode: zipcode,
+			Type:             request.Recipient.Type,
+			Country:          "BRA",
+			Zipcode:          zipcode,
+			RegisteredNumber: request.Recipient.RegisteredNumber,
 		},
-		Dispatchers: []quote.Dispatcher{{
-			RegisteredNumber: cfg.RegisteredNumber,
-			Zipcode:          1311000,
-			Volumes: func() []quote.Volume {
-				var volumes []quote.Volume
-				for _, v := range request.Volumes {
-					volume := quote.Volume{
-						Category:      strconv.Itoa(v.Category),
-						Amount:        v.Amount,
-						UnitaryWeight: v.UnitaryWeight,
-						Width:         v.Width,
-						Height:        v.Height,
-						Length:        v.Length,
-						UnitaryPrice:  v.Price,
-					}
-					volumes = append(volumes, volume)
-				}
-				return volumes
-			}(),
-		}},
+		Dispatchers: dispatchers,
 	}, nil
 }
 
-func DomainToSimulateQuoteResponse(offer []quote.Offer) SimulateQuoteResponse {
+func domainToCarriers(offers []quote.Offer) []Carrier {
+	var carriers []Carrier
+	for _, o := range offers {
+		carrier := Carrier{
+			Name:        o.Carrier,
+			Service:     o.Service,
+			Deadline:    o.DeliveryTime,
+			Price:       o.FinalPrice,
+			Source:      o.Source,
+			WarehouseID: o.WarehouseID,
+		}
+		carriers = append(carriers, carrier)
+	}
+	return carriers
+}
+
+func domainToCombination(combination *quote.OfferCombination) *CombinationResponse {
+	if combination == nil {
+		return nil
+	}
+	return &CombinationResponse{
+		Price:    combination.TotalPrice,
+		Deadline: combination.DeliveryTime,
+		Carrier:  domainToCarriers(combination.Offers),
+	}
+}
+
+func DomainToSimulateQuoteResponse(dispatchers []quote.Dispatcher, offers []quote.Offer) SimulateQuoteResponse {
+	groups := quote.GroupOffersByWarehouse(dispatchers, offers)
 	return SimulateQuoteResponse{
-		Carrier: func() []Carrier {
-			var carriers []Carrier
-			for _, o := range offer {
-				carrier := Carrier{
-					Name:     o.Carrier,
-					Service:  o.Service,
-					Deadline: o.DeliveryTime,
-					Price:    o.FinalPrice,
-				}
-				carriers = append(carriers, carrier)
+		Carrier: domainToCarriers(offers),
+		Dispatchers: func() []DispatcherOffersResponse {
+			var dispatchersResponse []DispatcherOffersResponse
+			for _, g := range groups {
+				dispatchersResponse = append(dispatchersResponse, DispatcherOffersResponse{
+					WarehouseID: g.WarehouseID,
+					Carrier:     domainToCarriers(g.Offers),
+				})
 			}
-			return carriers
+			return dispatchersResponse
 		}(),
+		Cheapest: domainToCombination(quote.CheapestCombination(groups)),
+		Fastest:  domainToCombination(quote.FastestCombination(groups)),
 	}
 }
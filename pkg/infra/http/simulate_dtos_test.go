@@ -0,0 +1,92 @@
+package http
+
+import (
+	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRequestToDomainQuoteSplitsVolumesByWarehouse(t *testing.T) {
+	sp := quote.Warehouse{ID: "sp-01", RegisteredNumber: "25438296000158", Zipcode: 1311000, Active: true}
+	se := quote.Warehouse{ID: "se-01", RegisteredNumber: "11222333000181", Zipcode: 49157021, Active: true}
+	request := SimulateQuoteRequest{
+		Recipient: RecipientRequest{Address: Address{Zipcode: "01311000"}},
+		Volumes: []VolumeRequest{
+			{Category: 7, Amount: 1, Sku: "a"},
+			{Category: 7, Amount: 2, Sku: "b", WarehouseID: "se-01"},
+			{Category: 7, Amount: 3, Sku: "c"},
+		},
+	}
+
+	shipper := quote.Shipper{RegisteredNumber: "25438296000158", Token: "token-de-teste-com-32-caracteres", PlatformCode: "5AKVkHqCn"}
+
+	quoteRequest, err := RequestToDomainQuote(request, shipper, sp, map[string]quote.Warehouse{"sp-01": sp, "se-01": se})
+
+	assert.Nil(t, err)
+	assert.Equal(t, shipper, quoteRequest.Shipper)
+	assert.Equal(t, 2, len(quoteRequest.Dispatchers))
+	assert.Equal(t, "sp-01", quoteRequest.Dispatchers[0].WarehouseID)
+	assert.Equal(t, 2, len(quoteRequest.Dispatchers[0].Volumes))
+	assert.Equal(t, "se-01", quoteRequest.Dispatchers[1].WarehouseID)
+	assert.Equal(t, 49157021, quoteRequest.Dispatchers[1].Zipcode)
+	assert.Equal(t, 2, quoteRequest.Dispatchers[1].Volumes[0].Amount)
+}
+
+func TestRequestToDomainQuoteKeepsRecipient(t *testing.T) {
+	sp := quote.Warehouse{ID: "sp-01", RegisteredNumber: "25438296000158", Zipcode: 1311000, Active: true}
+	request := SimulateQuoteRequest{
+		Recipient: RecipientRequest{Type: 1, RegisteredNumber: "11.222.333/0001-81", Address: Address{Zipcode: "01311000"}},
+		Volumes:   []VolumeRequest{{Category: 7, Amount: 1, Sku: "a"}},
+	}
+
+	quoteRequest, err := RequestToDomainQuote(request, quote.Shipper{}, sp, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, quote.Recipient{Type: 1, Country: "BRA", Zipcode: 1311000, RegisteredNumber: "11.222.333/0001-81"}, quoteRequest.Recipient)
+	contract := DomainToFreteRapidoContractRequest(*quoteRequest)
+	assert.Equal(t, 1, contract.Recipient.Type)
+	assert.Equal(t, "11.222.333/0001-81", contract.Recipient.RegisteredNumber)
+}
+
+func TestRequestToDomainQuoteRejectsUnresolvedWarehouse(t *testing.T) {
+	sp := quote.Warehouse{ID: "sp-01", RegisteredNumber: "25438296000158", Zipcode: 1311000, Active: true}
+	request := SimulateQuoteRequest{
+		Recipient: RecipientRequest{Address: Address{Zipcode: "01311000"}},
+		Volumes: []VolumeRequest{
+			{Category: 7, Amount: 1, Sku: "a"},
+			{Category: 7, Amount: 1, Sku: "b", WarehouseID: "inativo"},
+		},
+	}
+
+	_, err := RequestToDomainQuote(request, quote.Shipper{}, sp, map[string]quote.Warehouse{"sp-01": sp})
+
+	var validationErr *quote.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "volumes[1].warehouse_id", validationErr.Field)
+	status, response := DomainErrorToResponse(err)
+	assert.Equal(t, ErrCodeValidationFailed, response.Code)
+	assert.Less(t, status, 500)
+}
+
+func TestFreteApiResponseToDomainOfferKeepsOrigin(t *testing.T) {
+	request := quote.QuoteRequest{Dispatchers: []quote.Dispatcher{
+		{WarehouseID: "sp-01", RegisteredNumber: "25438296000158", Zipcode: 1311000},
+		{WarehouseID: "se-01", RegisteredNumber: "11222333000181", Zipcode: 49157021},
+	}}
+	response := FreteRapidoApiResponse{Dispatchers: []DispatcherResponse{
+		{
+			RegisteredNumberDispatcher: "11222333000181",
+			ZipcodeOrigin:              49157021,
+			Offer:                      []OfferResponse{{FinalPrice: 20, Carrier: CarrierResponse{Name: "CORREIOS"}}},
+		},
+		{
+			Offer: []OfferResponse{{FinalPrice: 30, Carrier: CarrierResponse{Name: "JADLOG"}}},
+		},
+	}}
+
+	offers := FreteApiResponseToDomainOffer(request, response)
+
+	assert.Equal(t, 2, len(offers))
+	assert.Equal(t, "se-01", offers[0].WarehouseID)
+	assert.Equal(t, "se-01", offers[1].WarehouseID)
+}
@@ -0,0 +1,130 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// New monta o logger do serviço no formato (text ou json) e nível (debug,
+// info, warn ou error) configurados. Toda linha recebe os campos guardados no
+// contexto da requisição e o trace_id do span corrente.
+func New(w io.Writer, format, level string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("nível de log inválido %q: use debug, info, warn ou error", level)
+	}
+	options := &slog.HandlerOptions{Level: lvl}
+
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "", FormatText:
+		handler = slog.NewTextHandler(w, options)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, options)
+	default:
+		return nil, fmt.Errorf("formato de log inválido %q: use text ou json", format)
+	}
+	return slog.New(ContextHandler{Handler: handler}), nil
+}
+
+// OrDefault devolve logger, ou o logger padrão do slog quando ele é nil.
+func OrDefault(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return slog.Default()
+	}
+	return logger
+}
+
+// contextFields é compartilhado por toda a requisição: campos descobertos no
+// meio do caminho (como o CEP) aparecem também nas linhas seguintes, inclusive
+// no log de acesso escrito ao final.
+type contextFields struct {
+	mu    sync.Mutex
+	attrs []slog.Attr
+}
+
+type contextFieldsKey struct{}
+
+// NewContext abre um novo conjunto de campos de log em ctx, herdando os que
+// já existirem.
+func NewContext(ctx context.Context, attrs ...slog.Attr) context.Context {
+	fields := &contextFields{attrs: append(Attrs(ctx), attrs...)}
+	return context.WithValue(ctx, contextFieldsKey{}, fields)
+}
+
+// AddAttrs acrescenta campos às próximas linhas de log da requisição. Sem um
+// contexto aberto por NewContext, não faz nada.
+func AddAttrs(ctx context.Context, attrs ...slog.Attr) {
+	fields, ok := ctx.Value(contextFieldsKey{}).(*contextFields)
+	if !ok {
+		return
+	}
+	fields.mu.Lock()
+	defer fields.mu.Unlock()
+	fields.attrs = append(fields.attrs, attrs...)
+}
+
+// Attrs devolve uma cópia dos campos guardados em ctx.
+func Attrs(ctx context.Context) []slog.Attr {
+	fields, ok := ctx.Value(contextFieldsKey{}).(*contextFields)
+	if !ok {
+		return nil
+	}
+	fields.mu.Lock()
+	defer fields.mu.Unlock()
+	return append([]slog.Attr(nil), fields.attrs...)
+}
+
+type requestIDKey struct{}
+
+// WithRequestID guarda em ctx o X-Request-ID da requisição, para que ele
+// chegue também ao que é gravado no banco.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID devolve o X-Request-ID guardado em ctx, ou vazio.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// ContextHandler acrescenta a cada registro os campos do contexto e o
+// trace_id, para correlacionar logs e traces da mesma requisição, e troca os
+// segredos registrados em RegisterSecret por Redacted.
+type ContextHandler struct {
+	slog.Handler
+}
+
+func (h ContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, Redact(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(attr))
+		return true
+	})
+	if ctx != nil {
+		redacted.AddAttrs(redactAttrs(Attrs(ctx))...)
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			redacted.AddAttrs(slog.String("trace_id", sc.TraceID().String()))
+		}
+	}
+	return h.Handler.Handle(ctx, redacted)
+}
+
+func (h ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return ContextHandler{Handler: h.Handler.WithAttrs(redactAttrs(attrs))}
+}
+
+func (h ContextHandler) WithGroup(name string) slog.Handler {
+	return ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,81 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/telemetry"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"testing"
+)
+
+func TestNewJSONIncludesContextFieldsAndTraceID(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, FormatJSON, "info")
+	assert.Nil(t, err)
+	shutdown, err := telemetry.SetupTracing(context.Background(), telemetry.TracesExporterNone)
+	assert.Nil(t, err)
+	defer shutdown(context.Background())
+
+	ctx := NewContext(context.Background(), slog.String("request_id", "abc-123"), slog.String("route", "/simulate"))
+	ctx, span := telemetry.StartSpan(ctx, "SimulateQuote")
+	defer span.End()
+	AddAttrs(ctx, slog.Int("zipcode", 1311000))
+	logger.WarnContext(ctx, "cotação parcial, provedor falhou")
+
+	var line map[string]any
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "WARN", line["level"])
+	assert.Equal(t, "abc-123", line["request_id"])
+	assert.Equal(t, "/simulate", line["route"])
+	assert.Equal(t, float64(1311000), line["zipcode"])
+	assert.Equal(t, span.SpanContext().TraceID().String(), line["trace_id"])
+}
+
+func TestNewRespectsLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, FormatText, "warn")
+	assert.Nil(t, err)
+
+	logger.Info("ignorada")
+	logger.Error("registrada")
+
+	assert.NotContains(t, buf.String(), "ignorada")
+	assert.Contains(t, buf.String(), "registrada")
+}
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	_, err := New(&bytes.Buffer{}, "xml", "info")
+	assert.Error(t, err)
+
+	_, err = New(&bytes.Buffer{}, FormatJSON, "verbose")
+	assert.Error(t, err)
+}
+
+func TestAddAttrsWithoutContextFieldsIsNoop(t *testing.T) {
+	ctx := context.Background()
+	AddAttrs(ctx, slog.String("request_id", "abc"))
+	assert.Empty(t, Attrs(ctx))
+}
+
+func TestContextHandlerRedactsSecrets(t *testing.T) {
+	const token = "token-de-teste-com-32-caracteres"
+	RegisterSecret(token)
+	var buf bytes.Buffer
+	logger, _ := New(&buf, FormatJSON, "info")
+
+	ctx := NewContext(context.Background(), slog.String("payload", `{"token":"`+token+`"}`))
+	logger.With(slog.String("shipper", token)).ErrorContext(ctx, "token "+token+" recusado",
+		slog.String("error", "invalid token "+token),
+		slog.Any("err", errors.New(token)),
+		slog.Group("request", slog.String("token", token)),
+	)
+
+	assert.NotContains(t, buf.String(), token)
+	assert.Contains(t, buf.String(), Redacted)
+	var line map[string]any
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "token [REDACTED] recusado", line["msg"])
+}
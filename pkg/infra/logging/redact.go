@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// Redacted substitui segredos em logs, respostas de erro e dumps de
+// configuração.
+const Redacted = "[REDACTED]"
+
+var secrets struct {
+	mu       sync.RWMutex
+	values   []string
+	replacer *strings.Replacer
+}
+
+// RegisterSecret guarda valores que nunca podem sair em logs ou em respostas
+// ao cliente, como o token da Frete Rápido. Valores vazios são ignorados.
+func RegisterSecret(values ...string) {
+	secrets.mu.Lock()
+	defer secrets.mu.Unlock()
+	for _, value := range values {
+		if value != "" {
+			secrets.values = append(secrets.values, value)
+		}
+	}
+	pairs := make([]string, 0, 2*len(secrets.values))
+	for _, value := range secrets.values {
+		pairs = append(pairs, value, Redacted)
+	}
+	secrets.replacer = strings.NewReplacer(pairs...)
+}
+
+// Redact troca em s cada segredo registrado por Redacted.
+func Redact(s string) string {
+	secrets.mu.RLock()
+	replacer := secrets.replacer
+	secrets.mu.RUnlock()
+	if replacer == nil {
+		return s
+	}
+	return replacer.Replace(s)
+}
+
+func redactAttrs(attrs []slog.Attr) []slog.Attr {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = redactAttr(attr)
+	}
+	return redacted
+}
+
+func redactAttr(attr slog.Attr) slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		attr.Value = slog.StringValue(Redact(attr.Value.String()))
+	case slog.KindGroup:
+		attr.Value = slog.GroupValue(redactAttrs(attr.Value.Group())...)
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok {
+			attr.Value = slog.StringValue(Redact(err.Error()))
+		}
+	}
+	return attr
+}
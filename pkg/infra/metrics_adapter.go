@@ -1,6 +1,8 @@
 package infra
 
 import (
+	"context"
+
 	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
 	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/database"
 )
@@ -15,6 +17,6 @@ func NewMetricsAdapter(repo database.IQuoteRepository) *MetricsAdapter {
 	}
 }
 
-func (m MetricsAdapter) Execute(lastQuotes int) (*quote.Metrics, error) {
-	return m.repo.GetMetricsQuotes(lastQuotes)
+func (m MetricsAdapter) Execute(ctx context.Context, filter quote.MetricsFilter) (*quote.Metrics, error) {
+	return m.repo.GetMetricsQuotes(ctx, filter)
 }
@@ -0,0 +1,93 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/logging"
+)
+
+// CarrierProvider associa um nome de origem a um provedor de cotações.
+type CarrierProvider struct {
+	Name     string
+	Provider quote.SimulateQuoteOutPutPort
+}
+
+// MultiCarrierAdapter distribui a mesma cotação para todos os provedores
+// registrados e junta as ofertas retornadas.
+type MultiCarrierAdapter struct {
+	providers []CarrierProvider
+	logger    *slog.Logger
+}
+
+func NewMultiCarrierAdapter(logger *slog.Logger, providers ...CarrierProvider) *MultiCarrierAdapter {
+	return &MultiCarrierAdapter{
+		providers: providers,
+		logger:    logging.OrDefault(logger),
+	}
+}
+
+type providerResult struct {
+	offers []quote.Offer
+	err    error
+}
+
+func (m *MultiCarrierAdapter) Execute(ctx context.Context, quoteData quote.QuoteRequest) ([]quote.Offer, error) {
+	simulation, err := m.Simulate(ctx, quoteData)
+	if err != nil {
+		return nil, err
+	}
+	return simulation.Offers, nil
+}
+
+// Simulate é o Execute marcando como Partial o resultado em que algum
+// provedor falhou, para que ele não seja guardado como se fosse completo.
+func (m *MultiCarrierAdapter) Simulate(ctx context.Context, quoteData quote.QuoteRequest) (*quote.Simulation, error) {
+	if len(m.providers) == 0 {
+		return nil, errors.New("nenhum provedor de cotação registrado")
+	}
+
+	results := make([]providerResult, len(m.providers))
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i, p := range m.providers {
+		wg.Add(1)
+		go func(i int, p CarrierProvider) {
+			defer wg.Done()
+			offers, err := p.Provider.Execute(ctx, quoteData)
+			results[i] = providerResult{offers: offers, err: err}
+		}(i, p)
+	}
+	wg.Wait()
+	latency := time.Since(start)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var offers []quote.Offer
+	var errs []error
+	for i, result := range results {
+		name := m.providers[i].Name
+		if result.err != nil {
+			errs = append(errs, fmt.Errorf("provedor %s: %w", name, result.err))
+			continue
+		}
+		for _, offer := range result.offers {
+			offer.Source = name
+			offers = append(offers, offer)
+		}
+	}
+
+	if len(errs) == len(m.providers) {
+		return nil, errors.Join(errs...)
+	}
+	for _, err := range errs {
+		m.logger.WarnContext(ctx, "cotação parcial, provedor falhou", slog.String("error", err.Error()))
+	}
+	return &quote.Simulation{Offers: offers, Partial: len(errs) > 0, UpstreamLatency: latency}, nil
+}
@@ -0,0 +1,35 @@
+package infra
+
+import (
+	"context"
+	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/database"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/logging"
+)
+
+type QuoteHistoryAdapter struct {
+	repo database.IQuoteRepository
+}
+
+func NewQuoteHistoryAdapter(repo database.IQuoteRepository) *QuoteHistoryAdapter {
+	return &QuoteHistoryAdapter{
+		repo: repo,
+	}
+}
+
+// SaveQuote grava a cotação com o X-Request-ID da requisição, para
+// correlacioná-la com os logs.
+func (h *QuoteHistoryAdapter) SaveQuote(ctx context.Context, quoteData *quote.Quote) error {
+	if quoteData.RequestID == "" {
+		quoteData.RequestID = logging.RequestID(ctx)
+	}
+	return h.repo.SaveQuote(ctx, quoteData)
+}
+
+func (h *QuoteHistoryAdapter) GetQuote(ctx context.Context, id string) (*quote.Quote, error) {
+	return h.repo.GetQuote(ctx, id)
+}
+
+func (h *QuoteHistoryAdapter) ListQuotes(ctx context.Context, filter quote.QuoteFilter) (*quote.QuotePage, error) {
+	return h.repo.ListQuotes(ctx, filter)
+}
@@ -0,0 +1,28 @@
+package infra
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy define quantas vezes uma chamada é repetida e o backoff
+// exponencial com jitter entre as tentativas.
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// Backoff retorna um atraso aleatório entre zero e min(MaxDelay, BaseDelay*2^attempt).
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := p.MaxDelay
+	if attempt < 32 {
+		if exp := p.BaseDelay << attempt; exp > 0 && exp < p.MaxDelay {
+			delay = exp
+		}
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return rand.N(delay)
+}
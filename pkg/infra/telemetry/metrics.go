@@ -0,0 +1,71 @@
+package telemetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Default é o registro exposto em /internal/metrics. Além das métricas do
+// serviço traz as go_* e process_* do runtime.
+var Default = prometheus.NewRegistry()
+
+func init() {
+	Default.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+var factory = promauto.With(Default)
+
+var (
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "freight_http_requests_total",
+		Help: "Requisições HTTP atendidas, por rota e status.",
+	}, []string{"method", "route", "status"})
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "freight_http_request_duration_seconds",
+		Help:    "Latência das requisições HTTP, por rota.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	FreteRapidoRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "freight_frete_rapido_requests_total",
+		Help: "Chamadas à Frete Rápido, por status HTTP (\"error\" quando não houve resposta).",
+	}, []string{"status"})
+	FreteRapidoDuration = factory.NewHistogram(prometheus.HistogramOpts{
+		Name:    "freight_frete_rapido_request_duration_seconds",
+		Help:    "Latência de cada tentativa de chamada à Frete Rápido.",
+		Buckets: prometheus.DefBuckets,
+	})
+	FreteRapidoCircuitState = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "freight_frete_rapido_circuit_state",
+		Help: "Estado do circuit breaker da Frete Rápido: 0 fechado, 1 aberto, 2 half-open.",
+	})
+	FreteRapidoCircuitOpens = factory.NewCounter(prometheus.CounterOpts{
+		Name: "freight_frete_rapido_circuit_opens_total",
+		Help: "Vezes em que o circuit breaker da Frete Rápido abriu.",
+	})
+
+	CacheOperations = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "freight_cache_operations_total",
+		Help: "Operações no cache de cotações, por tipo e resultado (hit, miss, ok ou error).",
+	}, []string{"operation", "result"})
+
+	DBWriteDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "freight_db_write_duration_seconds",
+		Help:    "Latência das escritas no banco, por operação.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"operation"})
+	DBWriteFailures = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "freight_db_write_failures_total",
+		Help: "Escritas no banco que falharam, por operação.",
+	}, []string{"operation"})
+
+	OffersPerQuote = factory.NewHistogram(prometheus.HistogramOpts{
+		Name:    "freight_offers_per_quote",
+		Help:    "Quantidade de ofertas devolvidas por simulação.",
+		Buckets: []float64{0, 1, 2, 3, 5, 8, 13, 21},
+	})
+)
@@ -0,0 +1,22 @@
+package telemetry
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDefault_IncludesRuntimeAndServiceMetrics(t *testing.T) {
+	HTTPRequests.WithLabelValues("GET", "/quotes/:id", "200").Inc()
+
+	families, err := Default.Gather()
+	require.NoError(t, err)
+	names := make(map[string]bool)
+	for _, family := range families {
+		names[family.GetName()] = true
+	}
+
+	assert.True(t, names["go_goroutines"])
+	assert.True(t, names["process_cpu_seconds_total"])
+	assert.True(t, names["freight_http_requests_total"])
+}
@@ -0,0 +1,109 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// ServiceName é o service.name dos spans, a menos que OTEL_SERVICE_NAME
+	// diga outro.
+	ServiceName         = "freight-quote"
+	instrumentationName = "github.com/pgabrielgmdeveloper/freightQuote"
+)
+
+// Valores aceitos em OTEL_TRACES_EXPORTER.
+const (
+	TracesExporterNone    = "none"
+	TracesExporterOTLP    = "otlp"
+	TracesExporterConsole = "console"
+)
+
+// SetupTracing instala o TracerProvider do SDK e o propagador W3C Trace
+// Context globais. Com otlp os spans vão ao coletor configurado pelas
+// variáveis padrão OTEL_EXPORTER_OTLP_* (protocolo http/protobuf, a menos que
+// OTEL_EXPORTER_OTLP_PROTOCOL diga grpc); com console saem em JSON no stderr,
+// separados dos logs; com none não são exportados, mas continuam sendo criados
+// para que o traceparent chegue à Frete Rápido e os logs tenham trace_id.
+//
+// A função devolvida envia os spans pendentes e deve ser chamada ao encerrar.
+func SetupTracing(ctx context.Context, exporter string) (func(context.Context) error, error) {
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(ServiceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível montar o resource dos traces: %w", err)
+	}
+	options := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	spanExporter, err := newSpanExporter(ctx, exporter)
+	if err != nil {
+		return nil, err
+	}
+	if spanExporter != nil {
+		options = append(options, sdktrace.WithBatcher(spanExporter))
+	}
+	provider := sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+func newSpanExporter(ctx context.Context, kind string) (sdktrace.SpanExporter, error) {
+	switch kind {
+	case "", TracesExporterNone:
+		return nil, nil
+	case TracesExporterConsole:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stderr))
+	case TracesExporterOTLP:
+		switch protocol := otlpProtocol(); protocol {
+		case "grpc":
+			return otlptracegrpc.New(ctx)
+		case "http/protobuf":
+			return otlptracehttp.New(ctx)
+		default:
+			return nil, fmt.Errorf("protocolo OTLP não suportado %q: use grpc ou http/protobuf", protocol)
+		}
+	}
+	return nil, fmt.Errorf("exportador de traces desconhecido %q: use otlp, console ou none", kind)
+}
+
+// otlpProtocol segue a precedência da especificação: a variável específica de
+// traces, depois a geral.
+func otlpProtocol() string {
+	for _, key := range []string{"OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "OTEL_EXPORTER_OTLP_PROTOCOL"} {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+	}
+	return "http/protobuf"
+}
+
+// StartSpan inicia um span filho do span corrente de ctx no TracerProvider
+// global.
+func StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// RecordError registra err no span e o marca como falho. Erros nil são
+// ignorados.
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
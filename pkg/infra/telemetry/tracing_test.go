@@ -0,0 +1,68 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"testing"
+)
+
+func TestSetupTracingContinuesRemoteTrace(t *testing.T) {
+	shutdown, err := SetupTracing(context.Background(), TracesExporterNone)
+	assert.Nil(t, err)
+	defer shutdown(context.Background())
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(header))
+	ctx, span := StartSpan(ctx, "GET /quotes")
+	defer span.End()
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	injected := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(injected))
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-"+span.SpanContext().SpanID().String()+"-01", injected.Get("traceparent"))
+}
+
+func TestSetupTracingRejectsUnknownExporter(t *testing.T) {
+	_, err := SetupTracing(context.Background(), "jaeger")
+	assert.Error(t, err)
+
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/json")
+	_, err = SetupTracing(context.Background(), TracesExporterOTLP)
+	assert.Error(t, err)
+}
+
+func TestRecordError(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+
+	ctx, root := StartSpan(context.Background(), "SimulateQuote")
+	_, child := StartSpan(ctx, "QuoteRepository.SaveQuote")
+	child.SetAttributes(attribute.Int("quote.offers", 3))
+	RecordError(child, errors.New("conexão recusada"))
+	child.End()
+	RecordError(root, nil)
+	root.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	saved, simulate := spans[0], spans[1]
+	assert.Equal(t, simulate.SpanContext().SpanID(), saved.Parent().SpanID())
+	assert.Equal(t, codes.Error, saved.Status().Code)
+	assert.Equal(t, "conexão recusada", saved.Status().Description)
+	assert.Len(t, saved.Events(), 1)
+	assert.Equal(t, codes.Unset, simulate.Status().Code)
+	assert.Equal(t, trace.SpanKindInternal, saved.SpanKind())
+}
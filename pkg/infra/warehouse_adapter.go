@@ -0,0 +1,74 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pgabrielgmdeveloper/freightQuote/internal/domain/quote"
+	"github.com/pgabrielgmdeveloper/freightQuote/pkg/infra/database"
+)
+
+type WarehouseAdapter struct {
+	repo database.IWarehouseRepository
+}
+
+func NewWarehouseAdapter(repo database.IWarehouseRepository) *WarehouseAdapter {
+	return &WarehouseAdapter{
+		repo: repo,
+	}
+}
+
+func (w *WarehouseAdapter) ListWarehouses(ctx context.Context) ([]quote.Warehouse, error) {
+	return w.repo.ListWarehouses(ctx)
+}
+
+// StaticWarehouseAdapter serve os centros de distribuição carregados da configuração.
+type StaticWarehouseAdapter struct {
+	warehouses []quote.Warehouse
+}
+
+func NewStaticWarehouseAdapter(warehouses []quote.Warehouse) *StaticWarehouseAdapter {
+	return &StaticWarehouseAdapter{
+		warehouses: warehouses,
+	}
+}
+
+func (s *StaticWarehouseAdapter) ListWarehouses(ctx context.Context) ([]quote.Warehouse, error) {
+	return append([]quote.Warehouse(nil), s.warehouses...), nil
+}
+
+type warehouseConfig struct {
+	ID               string `json:"id"`
+	Name             string `json:"name"`
+	RegisteredNumber string `json:"registered_number"`
+	Zipcode          int    `json:"zipcode"`
+	Active           *bool  `json:"active"`
+}
+
+// ParseWarehouses lê a lista de centros de distribuição no formato JSON usado
+// pela variável WAREHOUSES; quando "active" é omitido o centro fica ativo.
+// Problemas de cadastro voltam todos juntos como quote.ValidationErrors, com
+// o índice do centro no campo, ex.: "[1].zipcode".
+func ParseWarehouses(raw string) ([]quote.Warehouse, error) {
+	var configs []warehouseConfig
+	if err := json.Unmarshal([]byte(raw), &configs); err != nil {
+		return nil, fmt.Errorf("JSON inválido: %w", err)
+	}
+	warehouses := make([]quote.Warehouse, 0, len(configs))
+	var errs quote.ValidationErrors
+	for i, c := range configs {
+		warehouse := quote.Warehouse{
+			ID:               c.ID,
+			Name:             c.Name,
+			RegisteredNumber: quote.NormalizeRegisteredNumber(c.RegisteredNumber),
+			Zipcode:          c.Zipcode,
+			Active:           c.Active == nil || *c.Active,
+		}
+		errs.Merge(fmt.Sprintf("[%d]", i), warehouse.Validate())
+		warehouses = append(warehouses, warehouse)
+	}
+	if err := errs.ErrOrNil(); err != nil {
+		return nil, err
+	}
+	return warehouses, nil
+}